@@ -134,6 +134,14 @@ func (bs *BoolOrString) Set(value interface{}) error {
 	return bs.str.Set(value)
 }
 
+// Value returns the underlying bool or string, or nil if neither is set
+func (bs BoolOrString) Value() interface{} {
+	if !bs.boolean.IsNil() {
+		return bs.boolean.Value()
+	}
+	return bs.str.Value()
+}
+
 func (bs BoolOrString) String() string {
 	if !bs.str.IsNil() {
 		return bs.str.String()
@@ -0,0 +1,139 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Canonicalize returns d encoded per the JSON Canonicalization Scheme (RFC
+// 8785). Whitespace is removed, object members are sorted by the UTF-16 code
+// units of their keys, numbers are formatted as ECMAScript would and strings
+// are minimally escaped.
+//
+// An error is returned if d is not valid json, contains duplicate keys or
+// numbers which can not be represented as an IEEE 754 double.
+func (d JSON) Canonicalize() (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	d = d.trimSpace()
+	return appendCanonical(make(JSON, 0, len(d)), d)
+}
+
+// MarshalCanonical returns the json encoding of v per the JSON
+// Canonicalization Scheme (RFC 8785). v is first encoded with encoding/json so
+// Map, Number, Time, String and the other dynamic types produce the same
+// output for equal values.
+func MarshalCanonical(v interface{}) (JSON, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(data).Canonicalize()
+}
+
+func appendCanonical(dst []byte, v JSON) ([]byte, error) {
+	switch v.Kind() {
+	case KindString:
+		s, err := unquote(v)
+		if err != nil {
+			return nil, err
+		}
+		return appendQuote(dst, s), nil
+	case KindNumber:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: number %s can not be canonicalized", ErrInvalidValue, v)
+		}
+		return appendCanonicalNumber(dst, f), nil
+	case KindNull, KindBool:
+		return append(dst, v...), nil
+	}
+	c, err := scanContainer(v, 0)
+	if err != nil {
+		return nil, err
+	}
+	if c.kind == KindArray {
+		dst = append(dst, '[')
+		for i, m := range c.members {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonical(dst, v[m.value.start:m.value.end]); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	}
+	members := c.members
+	keys := make([][]uint16, len(members))
+	for i, m := range members {
+		keys[i] = utf16.Encode([]rune(m.key))
+	}
+	sort.Sort(&utf16Members{members: members, keys: keys})
+	dst = append(dst, '{')
+	for i, m := range members {
+		if i > 0 {
+			if m.key == members[i-1].key {
+				return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidValue, m.key)
+			}
+			dst = append(dst, ',')
+		}
+		dst = appendQuote(dst, m.key)
+		dst = append(dst, ':')
+		if dst, err = appendCanonical(dst, v[m.value.start:m.value.end]); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// appendCanonicalNumber formats f as ECMAScript's Number.prototype.toString
+// does.
+func appendCanonicalNumber(dst []byte, f float64) []byte {
+	if f == 0 {
+		return append(dst, '0')
+	}
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.AppendFloat(dst, f, 'f', -1, 64)
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'e', -1, 64)
+	// ECMAScript does not zero pad exponents: 1e-07 becomes 1e-7
+	n := len(dst)
+	if n-start >= 4 && dst[n-4] == 'e' && dst[n-2] == '0' {
+		dst[n-2] = dst[n-1]
+		dst = dst[:n-1]
+	}
+	return dst
+}
+
+// utf16Members sorts members by the UTF-16 code units of their keys
+type utf16Members struct {
+	members []member
+	keys    [][]uint16
+}
+
+func (s *utf16Members) Len() int {
+	return len(s.members)
+}
+
+func (s *utf16Members) Less(i, j int) bool {
+	a, b := s.keys[i], s.keys[j]
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func (s *utf16Members) Swap(i, j int) {
+	s.members[i], s.members[j] = s.members[j], s.members[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
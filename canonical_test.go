@@ -0,0 +1,57 @@
+package dynamic_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONCanonicalize(t *testing.T) {
+	assert := require.New(t)
+	tests := map[string]string{
+		`{ "b": 2, "a": [ 1.0, "x" ] }`:                                                    `{"a":[1,"x"],"b":2}`,
+		`"\u20ac\u0041\n\u001f\/<"`:                                                        "\"€A\\n\\u001f/<\"",
+		`{"\u20ac":1,"\r":2,"1":3,"\ud83d\ude00":4,"\u00f6":5}`:                            "{\"\\r\":2,\"1\":3,\"ö\":5,\"€\":1,\"😀\":4}",
+		`[1e21, 1e-7, 0.000001, -0, 1E+2, 333333333.33333329, 4.50, 2e-3, 1e+30, -5e-324]`: `[1e+21,1e-7,0.000001,0,100,333333333.3333333,4.5,0.002,1e+30,-5e-324]`,
+		`[9007199254740993, 295147905179352830000]`:                                        `[9007199254740992,295147905179352830000]`,
+		` true `: `true`,
+	}
+	for input, expected := range tests {
+		res, err := dynamic.JSON(input).Canonicalize()
+		assert.NoError(err, input)
+		assert.Equal(expected, string(res), input)
+	}
+
+	_, err := dynamic.JSON(`{"a":1,"a":2}`).Canonicalize()
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.JSON(`1e400`).Canonicalize()
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.JSON(`{`).Canonicalize()
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	assert := require.New(t)
+	i, err := dynamic.NewNumber(1)
+	assert.NoError(err)
+	f, err := dynamic.NewNumber(1.0)
+	assert.NoError(err)
+	a, err := dynamic.MarshalCanonical(dynamic.Map{"n": i, "s": "<a&b>"})
+	assert.NoError(err)
+	b, err := dynamic.MarshalCanonical(map[string]interface{}{"s": "<a&b>", "n": f})
+	assert.NoError(err)
+	assert.Equal(`{"n":1,"s":"<a&b>"}`, string(a))
+	assert.Equal(string(a), string(b))
+
+	var tm dynamic.Time
+	assert.NoError(tm.Set(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)))
+	res, err := dynamic.MarshalCanonical(struct {
+		T dynamic.Time `json:"t"`
+		F float64      `json:"f"`
+	}{tm, math.Pi})
+	assert.NoError(err)
+	assert.Equal(`{"f":3.141592653589793,"t":"2021-01-02T03:04:05Z"}`, string(res))
+}
@@ -0,0 +1,185 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrDuplicateKey is wrapped by DuplicateKeyError
+var ErrDuplicateKey = errors.New("dynamic: duplicate key")
+
+// DuplicateKeyError is returned when an object contains a key more than once
+// and DecodeOptions.DuplicateKeys is DuplicateKeysError.
+type DuplicateKeyError struct {
+	Key string
+	// Path is the JSON Pointer of the duplicated member
+	Path string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%v %q at %q", ErrDuplicateKey, e.Key, e.Path)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// DuplicateKeyPolicy determines how objects containing a key more than once
+// are decoded
+type DuplicateKeyPolicy uint8
+
+const (
+	// DuplicateKeysLastWins keeps the last value of a duplicated key. This is
+	// the behavior of encoding/json.
+	DuplicateKeysLastWins DuplicateKeyPolicy = iota
+	// DuplicateKeysFirstWins keeps the first value of a duplicated key
+	DuplicateKeysFirstWins
+	// DuplicateKeysError fails with a *DuplicateKeyError
+	DuplicateKeysError
+	// DuplicateKeysCollect replaces the values of a duplicated key with an
+	// array of every value, in order
+	DuplicateKeysCollect
+)
+
+func (p DuplicateKeyPolicy) String() string {
+	switch p {
+	case DuplicateKeysLastWins:
+		return "last-wins"
+	case DuplicateKeysFirstWins:
+		return "first-wins"
+	case DuplicateKeysError:
+		return "error"
+	case DuplicateKeysCollect:
+		return "collect"
+	default:
+		return "DuplicateKeyPolicy(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// DecodeOptions configures the decoding of json. The zero value decodes as
+// encoding/json does.
+type DecodeOptions struct {
+	// DuplicateKeys is the policy for objects which contain a key more than
+	// once. It applies to every object within the document, including those
+	// retained as raw JSON such as the values of a JSONObject.
+	DuplicateKeys DuplicateKeyPolicy
+
+	// The following limits guard against untrusted input. Each is enforced
+	// while scanning, before anything is decoded, and results in a
+	// *LimitError. A limit of 0 is unlimited, with the exception of MaxDepth.
+
+	// MaxBytes is the maximum size of the document
+	MaxBytes int
+	// MaxDepth is the maximum nesting of objects and arrays. MaxNestingDepth
+	// is used if MaxDepth is 0 or greater than MaxNestingDepth.
+	MaxDepth int
+	// MaxStringLength is the maximum length, in encoded bytes and excluding
+	// quotes, of strings, including object keys
+	MaxStringLength int
+	// MaxNumberDigits is the maximum number of digits in a number, including
+	// those of the fraction and exponent
+	MaxNumberDigits int
+	// MaxMembers is the maximum number of members of an object
+	MaxMembers int
+	// MaxElements is the maximum length of an array
+	MaxElements int
+}
+
+// Unmarshal decodes data into v, which may be any value accepted by
+// json.Unmarshal, such as a *JSON, *Map, *JSONObject or any of the dynamic
+// types, per opts.
+//
+// A *SyntaxError is returned if data is malformed and a *LimitError is
+// returned if it exceeds a limit of opts.
+func (opts DecodeOptions) Unmarshal(data []byte, v interface{}) error {
+	d, err := opts.normalize(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(d, v)
+}
+
+// normalize validates data and applies opts to it, returning json which
+// encoding/json decodes as opts describe. data is returned as is if nothing
+// needs to change.
+//
+// data is scanned once, by a limiter, which enforces the limits of opts and
+// records the containers with duplicate keys. Only those containers are
+// rewritten; every other value is copied as is.
+func (opts DecodeOptions) normalize(data []byte) (JSON, error) {
+	d := JSON(data)
+	if !opts.hasLimits() && opts.DuplicateKeys == DuplicateKeysLastWins {
+		if err := d.Validate(); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+	l, err := opts.scan(d)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.rewrites) == 0 {
+		return d, nil
+	}
+	s := newScanner(d)
+	s.skipSpace()
+	return l.rewrite(nil, l.rewrites[s.pos]), nil
+}
+
+// rewrite appends c to buf with the duplicate key policy applied to it and
+// to the containers within it which have duplicate keys
+func (l *limiter) rewrite(buf []byte, c container) []byte {
+	value := func(buf []byte, v span) []byte {
+		if nested, ok := l.rewrites[v.start]; ok {
+			return l.rewrite(buf, nested)
+		}
+		return append(buf, l.s.data[v.start:v.end]...)
+	}
+	if c.kind == KindArray {
+		buf = append(buf, '[')
+		for i, m := range c.members {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = value(buf, m.value)
+		}
+		return append(buf, ']')
+	}
+	var keys []string
+	values := make(map[string][]span, len(c.members))
+	for _, m := range c.members {
+		if _, exists := values[m.key]; !exists {
+			keys = append(keys, m.key)
+		}
+		values[m.key] = append(values[m.key], m.value)
+	}
+	buf = append(buf, '{')
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendQuote(buf, k)
+		buf = append(buf, ':')
+		vs := values[k]
+		switch {
+		case len(vs) == 1:
+			buf = value(buf, vs[0])
+		case l.opts.DuplicateKeys == DuplicateKeysCollect:
+			buf = append(buf, '[')
+			for j, v := range vs {
+				if j > 0 {
+					buf = append(buf, ',')
+				}
+				buf = value(buf, v)
+			}
+			buf = append(buf, ']')
+		case l.opts.DuplicateKeys == DuplicateKeysFirstWins:
+			buf = value(buf, vs[0])
+		default:
+			buf = value(buf, vs[len(vs)-1])
+		}
+	}
+	return append(buf, '}')
+}
@@ -0,0 +1,116 @@
+package dynamic_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOptionsDuplicateKeys(t *testing.T) {
+	assert := require.New(t)
+	data := []byte(`{"a": 1, "b": {"c": [{"d": 1, "d": 2}]}, "a": 3}`)
+
+	var m dynamic.Map
+	assert.NoError(dynamic.DecodeOptions{}.Unmarshal(data, &m))
+	assert.Equal(float64(3), m["a"])
+
+	var obj dynamic.JSONObject
+	assert.NoError(dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysFirstWins}.Unmarshal(data, &obj))
+	assert.Equal(`1`, string(obj["a"]))
+	assert.Equal(`{"c":[{"d":1}]}`, string(obj["b"]))
+
+	assert.NoError(dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysCollect}.Unmarshal(data, &obj))
+	assert.Equal(`[1,3]`, string(obj["a"]))
+	assert.Equal(`{"c":[{"d":[1,2]}]}`, string(obj["b"]))
+
+	m = nil
+	err := dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysError}.Unmarshal(data, &m)
+	var derr *dynamic.DuplicateKeyError
+	assert.True(errors.As(err, &derr))
+	assert.ErrorIs(err, dynamic.ErrDuplicateKey)
+	assert.Equal("d", derr.Key)
+	assert.Equal("/b/c/0/d", derr.Path)
+	assert.Nil(m)
+
+	err = dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysError}.Unmarshal([]byte(`{"ab": 1, "ab": 2}`), &m)
+	assert.True(errors.As(err, &derr))
+	assert.Equal("/ab", derr.Path)
+
+	unchanged := []byte(`{ "a" : [ 1 ] }`)
+	var d dynamic.JSON
+	assert.NoError(dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysError}.Unmarshal(unchanged, &d))
+	assert.Equal(string(unchanged), string(d))
+
+	assert.ErrorIs(dynamic.DecodeOptions{}.Unmarshal([]byte(`{"a":}`), &m), dynamic.ErrMalformedJSON)
+
+	// only containers with duplicate keys, and their ancestors, are rewritten
+	data = []byte(`[ {"x" : [ 1 ]}, {"a": {"b": 1, "b": { "k" : 2 }}} ]`)
+	assert.NoError(dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysCollect}.Unmarshal(data, &d))
+	assert.Equal(`[{"x" : [ 1 ]},{"a":{"b":[1,{ "k" : 2 }]}}]`, string(d))
+
+	// deep nesting is scanned once rather than once per level
+	depth := 5000
+	deep := strings.Repeat(`{"a":1,"a":`, depth) + "2" + strings.Repeat("}", depth)
+	assert.NoError(dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysFirstWins}.Unmarshal([]byte(deep), &d))
+	assert.Equal(`{"a":1}`, string(d))
+	err = dynamic.DecodeOptions{DuplicateKeys: dynamic.DuplicateKeysError}.Unmarshal([]byte(deep), &d)
+	assert.True(errors.As(err, &derr))
+	assert.Equal(strings.Repeat("/a", depth), derr.Path)
+}
+
+func TestDecodeOptionsLimits(t *testing.T) {
+	assert := require.New(t)
+	data := []byte(`{"a": [1, 2, 3], "b": {"c": {"d": "long string"}}, "n": -12.5e10}`)
+	tests := []struct {
+		opts  dynamic.DecodeOptions
+		limit string
+		path  string
+	}{
+		{dynamic.DecodeOptions{MaxBytes: 10}, "MaxBytes", ""},
+		{dynamic.DecodeOptions{MaxDepth: 2}, "MaxDepth", "/b/c"},
+		{dynamic.DecodeOptions{MaxStringLength: 5}, "MaxStringLength", "/b/c/d"},
+		{dynamic.DecodeOptions{MaxNumberDigits: 4}, "MaxNumberDigits", "/n"},
+		{dynamic.DecodeOptions{MaxMembers: 2}, "MaxMembers", ""},
+		{dynamic.DecodeOptions{MaxElements: 2}, "MaxElements", "/a"},
+	}
+	for _, test := range tests {
+		var m dynamic.Map
+		err := test.opts.Unmarshal(data, &m)
+		var lerr *dynamic.LimitError
+		assert.True(errors.As(err, &lerr), test.limit)
+		assert.ErrorIs(err, dynamic.ErrLimitExceeded)
+		assert.Equal(test.limit, lerr.Limit)
+		assert.Equal(test.path, lerr.Path, test.limit)
+	}
+
+	opts := dynamic.DecodeOptions{
+		MaxBytes:        len(data),
+		MaxDepth:        3,
+		MaxStringLength: 11,
+		MaxNumberDigits: 5,
+		MaxMembers:      3,
+		MaxElements:     3,
+	}
+	var obj dynamic.JSONObject
+	assert.NoError(opts.Unmarshal(data, &obj))
+	assert.Len(obj, 3)
+
+	var s dynamic.String
+	assert.ErrorIs(dynamic.DecodeOptions{MaxStringLength: 2}.Unmarshal([]byte(`"abc"`), &s), dynamic.ErrLimitExceeded)
+
+	assert.ErrorIs(dynamic.DecodeOptions{MaxDepth: 5}.Unmarshal([]byte(`{"a": [1,]}`), &obj), dynamic.ErrMalformedJSON)
+
+	deep := []byte(strings.Repeat("[", 5000000))
+	var v interface{}
+	for _, opts := range []dynamic.DecodeOptions{{MaxStringLength: 10}, {MaxDepth: 1 << 30}} {
+		err := opts.Unmarshal(deep, &v)
+		var lerr *dynamic.LimitError
+		assert.True(errors.As(err, &lerr))
+		assert.Equal("MaxDepth", lerr.Limit)
+		assert.Equal(dynamic.MaxNestingDepth, lerr.Max)
+	}
+	assert.ErrorIs(dynamic.DecodeOptions{}.Unmarshal(deep, &v), dynamic.ErrMalformedJSON)
+}
@@ -0,0 +1,121 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+)
+
+// SemanticEqual reports whether d and other represent the same json value,
+// regardless of formatting or the order of object members. Numbers are
+// compared by value, so 1, 1.0 and 1e0 are equal, and strings are compared
+// after escape sequences are decoded.
+//
+// SemanticEqual reports false if either d or other is not valid json.
+func (d JSON) SemanticEqual(other JSON) bool {
+	eq, err := equalJSON(d, other)
+	return err == nil && eq
+}
+
+// equalJSON reports whether a and b are semantically equal
+func equalJSON(a, b JSON) (bool, error) {
+	if err := a.Validate(); err != nil {
+		return false, err
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	av, err := decode(a)
+	if err != nil {
+		return false, err
+	}
+	bv, err := decode(b)
+	if err != nil {
+		return false, err
+	}
+	return equalValues(av, bv), nil
+}
+
+// equalValues reports whether the decoded values a and b are semantically
+// equal
+func equalValues(a, b interface{}) bool {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for k, av := range at {
+			bv, ok := bt[k]
+			if !ok || !equalValues(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !equalValues(at[i], bt[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bt, ok := b.(json.Number)
+		return ok && equalNumbers(string(at), string(bt))
+	default:
+		return a == b
+	}
+}
+
+// equalNumbers reports whether the json numbers a and b have the same value.
+// The comparison is exact; neither number is converted to a float.
+func equalNumbers(a, b string) bool {
+	if a == b {
+		return true
+	}
+	an, aok := normalizeNumber(a)
+	bn, bok := normalizeNumber(b)
+	return aok && bok && an == bn
+}
+
+type normalizedNumber struct {
+	negative bool
+	// digits are the significant digits without leading or trailing zeros
+	digits string
+	// exp is the decimal exponent applied to digits as an integer. It is
+	// arbitrarily large, as json does not bound exponents.
+	exp string
+}
+
+func normalizeNumber(s string) (normalizedNumber, bool) {
+	var n normalizedNumber
+	if strings.HasPrefix(s, "-") {
+		n.negative = true
+		s = s[1:]
+	}
+	exp := new(big.Int)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if _, ok := exp.SetString(s[i+1:], 10); !ok {
+			return n, false
+		}
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exp.Sub(exp, big.NewInt(int64(len(s)-i-1)))
+		s = s[:i] + s[i+1:]
+	}
+	s = strings.TrimLeft(s, "0")
+	trimmed := strings.TrimRight(s, "0")
+	exp.Add(exp, big.NewInt(int64(len(s)-len(trimmed))))
+	if trimmed == "" {
+		// all zeros, including -0
+		return normalizedNumber{}, true
+	}
+	n.digits = trimmed
+	n.exp = exp.String()
+	return n, true
+}
@@ -0,0 +1,46 @@
+package dynamic_test
+
+import (
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSemanticEqual(t *testing.T) {
+	assert := require.New(t)
+	equal := [][2]string{
+		{`{"a":1,"b":2}`, `{ "b":2, "a":1 }`},
+		{`1`, `1.0`},
+		{`1`, `1e0`},
+		{`100`, `1E+2`},
+		{`0.5`, `5e-1`},
+		{`0`, `-0.0`},
+		{`-12.50`, `-1.25e1`},
+		{`"é"`, `"é"`},
+		{`"a/b"`, `"a\/b"`},
+		{`[1, {"x": [true, null]}]`, "[1.00,{\"x\":[true,null]}]\n"},
+		{`18446744073709551615`, `1.8446744073709551615e19`},
+		{`1e99999999999999999999`, `10e99999999999999999998`},
+		{`1e-99999999999999999999`, `0.1e-99999999999999999998`},
+	}
+	for _, v := range equal {
+		assert.True(dynamic.JSON(v[0]).SemanticEqual(dynamic.JSON(v[1])), "%s == %s", v[0], v[1])
+		assert.True(dynamic.JSON(v[1]).SemanticEqual(dynamic.JSON(v[0])), "%s == %s", v[1], v[0])
+	}
+	unequal := [][2]string{
+		{`{"a":1}`, `{"a":1,"b":2}`},
+		{`1`, `-1`},
+		{`1`, `"1"`},
+		{`10`, `1`},
+		{`0.1`, `0.10000000000000001`},
+		{`9007199254740993`, `9007199254740992`},
+		{`1e99999999999999999999`, `1e99999999999999999998`},
+		{`[1,2]`, `[2,1]`},
+		{`null`, `false`},
+		{`{"a":1}`, `{"a":1`},
+	}
+	for _, v := range unequal {
+		assert.False(dynamic.JSON(v[0]).SemanticEqual(dynamic.JSON(v[1])), "%s != %s", v[0], v[1])
+	}
+}
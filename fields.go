@@ -0,0 +1,149 @@
+package dynamic
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structField is a field of a struct as named by its json tag
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]structField
+
+// fieldsOf returns the fields of the struct type t. Fields of embedded structs
+// are promoted per the rules of encoding/json: shallower fields take
+// precedence and fields of equal depth with the same name are dropped unless
+// exactly one of them is tagged.
+func fieldsOf(t reflect.Type) []structField {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]structField)
+	}
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []structField
+	named := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+	for current := []embedded{{typ: t}}; len(current) > 0; {
+		var next []embedded
+		var level []structField
+		count := map[string]int{}
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				f := e.typ.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int{}, e.index...), i)
+				ft := f.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					if f.IsExported() || f.Type.Kind() != reflect.Ptr {
+						next = append(next, embedded{typ: ft, index: index})
+					}
+					continue
+				}
+				if !f.IsExported() {
+					continue
+				}
+				sf := structField{
+					name:      name,
+					index:     index,
+					tagged:    name != "",
+					omitEmpty: hasTagOption(opts, "omitempty"),
+				}
+				if sf.name == "" {
+					sf.name = f.Name
+				}
+				level = append(level, sf)
+				count[sf.name]++
+			}
+		}
+		for _, sf := range level {
+			if named[sf.name] {
+				continue
+			}
+			if count[sf.name] > 1 && !soleTagged(level, sf) {
+				continue
+			}
+			fields = append(fields, sf)
+		}
+		for name := range count {
+			named[name] = true
+		}
+		current = next
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// soleTagged reports whether sf is the only tagged field of level with its
+// name
+func soleTagged(level []structField, sf structField) bool {
+	if !sf.tagged {
+		return false
+	}
+	for _, f := range level {
+		if f.name == sf.name && f.tagged && !equalIndex(f.index, sf.index) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTagOption(opts string, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the field of the struct v at index. Nil pointers to
+// embedded structs are allocated if alloc is true; otherwise, or if they can
+// not be set, false is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,315 @@
+package dynamic
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// flattenEscape escapes characters of keys which would otherwise be read as
+// part of a flattened path
+const flattenEscape = '\\'
+
+// FlattenOptions configures Flatten and Unflatten
+type FlattenOptions struct {
+	// Separator joins keys. Defaults to ".".
+	Separator string
+	// Brackets formats array indices as "a.b[0]" rather than "a.b.0"
+	Brackets bool
+}
+
+func (opts FlattenOptions) separator() string {
+	if opts.Separator == "" {
+		return "."
+	}
+	return opts.Separator
+}
+
+// escape escapes key so that it is read as a single key by Unflatten. The
+// separator, brackets and the escape character are escaped with a backslash
+// as is the first character of keys which would otherwise be read as an
+// array index.
+func (opts FlattenOptions) escape(key string) string {
+	sep := opts.separator()
+	var b strings.Builder
+	if !opts.Brackets && isIndexKey(key) {
+		b.WriteRune(flattenEscape)
+	}
+	for i := 0; i < len(key); {
+		switch {
+		case strings.HasPrefix(key[i:], sep):
+			for _, r := range sep {
+				b.WriteRune(flattenEscape)
+				b.WriteRune(r)
+			}
+			i += len(sep)
+			continue
+		case key[i] == flattenEscape || (opts.Brackets && (key[i] == '[' || key[i] == ']')):
+			b.WriteRune(flattenEscape)
+		}
+		b.WriteByte(key[i])
+		i++
+	}
+	return b.String()
+}
+
+// isIndexKey reports whether key is a canonical array index
+func isIndexKey(key string) bool {
+	_, ok := parseIndex(key)
+	return ok
+}
+
+// Flatten returns the leaf values of m keyed by their path, such as "a.b.0".
+// Empty objects and arrays are retained as leaves so that Unflatten restores
+// m.
+//
+// Nested Map, map[string]interface{}, JSONObject, []interface{} and JSON values
+// are flattened; values of any other type are leaves.
+func (m Map) Flatten(opts FlattenOptions) (Map, error) {
+	res := Map{}
+	for k, v := range m {
+		if err := opts.flatten(res, opts.escape(k), v); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Flatten decodes the json object d and returns its leaf values keyed by
+// their path. Numbers are decoded as json.Number so that Unflatten and
+// json.Marshal restore d without loss.
+func (d JSON) Flatten(opts FlattenOptions) (Map, error) {
+	v, err := decode(d)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: json is a %s, not an object", ErrInvalidType, d.Kind())
+	}
+	return Map(obj).Flatten(opts)
+}
+
+func (opts FlattenOptions) flatten(res Map, path string, v interface{}) error {
+	if raw, ok := asRawJSON(v); ok {
+		var err error
+		if v, err = decode(raw); err != nil {
+			return err
+		}
+	}
+	var obj map[string]interface{}
+	switch t := v.(type) {
+	case Map:
+		obj = t
+	case map[string]interface{}:
+		obj = t
+	case JSONObject:
+		obj = make(map[string]interface{}, len(t))
+		for k, v := range t {
+			obj[k] = v
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			res[path] = []interface{}{}
+			return nil
+		}
+		for i, e := range t {
+			p := path + opts.separator() + strconv.Itoa(i)
+			if opts.Brackets {
+				p = path + "[" + strconv.Itoa(i) + "]"
+			}
+			if err := opts.flatten(res, p, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		res[path] = v
+		return nil
+	}
+	if len(obj) == 0 {
+		res[path] = Map{}
+		return nil
+	}
+	for k, e := range obj {
+		if err := opts.flatten(res, path+opts.separator()+opts.escape(k), e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatToken is a key or array index of a flattened path
+type flatToken struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parse splits path into its tokens
+func (opts FlattenOptions) parse(path string) ([]flatToken, error) {
+	sep := opts.separator()
+	invalid := func() error {
+		return fmt.Errorf("%w: invalid path %q", ErrInvalidValue, path)
+	}
+	var tokens []flatToken
+	var key strings.Builder
+	escaped := false
+	afterIndex := false
+	endKey := func() {
+		t := flatToken{key: key.String()}
+		if !opts.Brackets && !escaped && len(tokens) > 0 {
+			t.index, t.isIndex = parseIndex(t.key)
+		}
+		tokens = append(tokens, t)
+		key.Reset()
+		escaped = false
+	}
+	for i := 0; i < len(path); {
+		c := path[i]
+		switch {
+		case c == flattenEscape:
+			if i+1 >= len(path) {
+				return nil, invalid()
+			}
+			key.WriteByte(path[i+1])
+			escaped = true
+			i += 2
+		case strings.HasPrefix(path[i:], sep):
+			if !afterIndex {
+				endKey()
+			}
+			afterIndex = false
+			i += len(sep)
+			if i == len(path) {
+				endKey()
+			}
+		case opts.Brackets && c == '[':
+			if !afterIndex {
+				endKey()
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, invalid()
+			}
+			idx, ok := parseIndex(path[i+1 : i+end])
+			if !ok || len(tokens) == 0 {
+				return nil, invalid()
+			}
+			tokens = append(tokens, flatToken{index: idx, isIndex: true})
+			afterIndex = true
+			i += end + 1
+		default:
+			if afterIndex {
+				return nil, invalid()
+			}
+			key.WriteByte(c)
+			i++
+		}
+	}
+	if !afterIndex && (key.Len() > 0 || escaped || len(tokens) == 0) {
+		endKey()
+	}
+	return tokens, nil
+}
+
+// MaxUnflattenIndex is the largest array index Unflatten accepts
+const MaxUnflattenIndex = 1<<16 - 1
+
+// Unflatten restores the nested Map from flat, the keys of which are paths as
+// produced by Flatten. Array elements which are missing are nil.
+//
+// An error wrapping ErrInvalidValue is returned if a path is malformed, if
+// paths conflict, such as "a" and "a.b", or if an array index exceeds
+// MaxUnflattenIndex.
+func Unflatten(flat Map, opts FlattenOptions) (Map, error) {
+	paths := make([]string, 0, len(flat))
+	for k := range flat {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+	var root interface{} = Map{}
+	for _, path := range paths {
+		tokens, err := opts.parse(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tokens {
+			if t.isIndex && t.index > MaxUnflattenIndex {
+				return nil, fmt.Errorf("%w: index %d of flattened path %q exceeds %d", ErrInvalidValue, t.index, path, MaxUnflattenIndex)
+			}
+		}
+		value := flat[path]
+		if value == nil {
+			value = flatNull{}
+		}
+		if root, err = unflatten(root, tokens, value); err != nil {
+			return nil, fmt.Errorf("%w: conflicting flattened path %q", ErrInvalidValue, path)
+		}
+	}
+	return resolveNulls(root).(Map), nil
+}
+
+// flatNull stands in for a nil value while unflattening so that it is not
+// mistaken for a missing one
+type flatNull struct{}
+
+// resolveNulls replaces every flatNull within v with nil
+func resolveNulls(v interface{}) interface{} {
+	switch t := v.(type) {
+	case flatNull:
+		return nil
+	case Map:
+		for k, e := range t {
+			t[k] = resolveNulls(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = resolveNulls(e)
+		}
+	}
+	return v
+}
+
+// unflatten sets value at tokens within v, returning the updated v
+func unflatten(v interface{}, tokens []flatToken, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		if v != nil {
+			return nil, ErrInvalidValue
+		}
+		return value, nil
+	}
+	t := tokens[0]
+	if t.isIndex {
+		if v == nil {
+			v = []interface{}{}
+		}
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, ErrInvalidValue
+		}
+		for len(arr) <= t.index {
+			arr = append(arr, nil)
+		}
+		e, err := unflatten(arr[t.index], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		arr[t.index] = e
+		return arr, nil
+	}
+	if v == nil {
+		v = Map{}
+	}
+	obj, ok := v.(Map)
+	if !ok {
+		return nil, ErrInvalidValue
+	}
+	e, err := unflatten(obj[t.key], tokens[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	obj[t.key] = e
+	return obj, nil
+}
@@ -0,0 +1,103 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{
+		"a": map[string]interface{}{
+			"b": []interface{}{1, 2},
+			"c": dynamic.Map{},
+		},
+		"d":     []interface{}{},
+		"e.f":   "dotted",
+		"0":     "numeric",
+		"g":     dynamic.JSON(`{"h": [true, {"i": null}]}`),
+		`j\k`:   "slash",
+		"l[0]":  "brackets",
+		"empty": "",
+	}
+	flat, err := m.Flatten(dynamic.FlattenOptions{})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{
+		"a.b.0":   1,
+		"a.b.1":   2,
+		"a.c":     dynamic.Map{},
+		"d":       []interface{}{},
+		`e\.f`:    "dotted",
+		`\0`:      "numeric",
+		"g.h.0":   true,
+		"g.h.1.i": nil,
+		`j\\k`:    "slash",
+		"l[0]":    "brackets",
+		"empty":   "",
+	}, flat)
+
+	flat, err = m.Flatten(dynamic.FlattenOptions{Separator: "/", Brackets: true})
+	assert.NoError(err)
+	assert.Equal(1, flat["a/b[0]"])
+	assert.Equal(nil, flat["g/h[1]/i"])
+	assert.Equal("dotted", flat["e.f"])
+	assert.Equal("numeric", flat["0"])
+	assert.Equal("brackets", flat[`l\[0\]`])
+}
+
+func TestUnflatten(t *testing.T) {
+	assert := require.New(t)
+	data := dynamic.JSON(`{
+		"a": {"b": [1, 2.50, {"c": "x"}], "0": "zero", "": {"": [[]]}},
+		"d.e": {"f[1]": {}},
+		"big": 12345678901234567890,
+		"esc\\": ["\\."]
+	}`)
+	for _, opts := range []dynamic.FlattenOptions{
+		{},
+		{Brackets: true},
+		{Separator: "::"},
+		{Separator: "::", Brackets: true},
+	} {
+		flat, err := data.Flatten(opts)
+		assert.NoError(err)
+		m, err := dynamic.Unflatten(flat, opts)
+		assert.NoError(err, "%+v", opts)
+		res, err := json.Marshal(m)
+		assert.NoError(err)
+		assert.True(data.SemanticEqual(res), "%+v: %s", opts, res)
+	}
+
+	m, err := dynamic.Unflatten(dynamic.Map{"a.b[2]": 1, "c": 2}, dynamic.FlattenOptions{Brackets: true})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"a": dynamic.Map{"b": []interface{}{nil, nil, 1}}, "c": 2}, m)
+
+	_, err = dynamic.Unflatten(dynamic.Map{"a": 1, "a.b": 2}, dynamic.FlattenOptions{})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.Unflatten(dynamic.Map{"a.0": 1, "a.b": 2}, dynamic.FlattenOptions{})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.Unflatten(dynamic.Map{"a": nil, "a.b": 1}, dynamic.FlattenOptions{})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.Unflatten(dynamic.Map{"a[0]": nil, "a[0].b": 1}, dynamic.FlattenOptions{Brackets: true})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	m, err = dynamic.Unflatten(dynamic.Map{"a": nil, "b[1]": nil}, dynamic.FlattenOptions{Brackets: true})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"a": nil, "b": []interface{}{nil, nil}}, m)
+	_, err = dynamic.Unflatten(dynamic.Map{"a[x]": 1}, dynamic.FlattenOptions{Brackets: true})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.Unflatten(dynamic.Map{`a\`: 1}, dynamic.FlattenOptions{})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.Unflatten(dynamic.Map{"a[1000000000]": 1}, dynamic.FlattenOptions{Brackets: true})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	_, err = dynamic.Unflatten(dynamic.Map{"a.65536": 1}, dynamic.FlattenOptions{})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	m, err = dynamic.Unflatten(dynamic.Map{"a.65535": 1}, dynamic.FlattenOptions{})
+	assert.NoError(err)
+	assert.Len(m["a"], dynamic.MaxUnflattenIndex+1)
+
+	_, err = dynamic.JSON(`[1]`).Flatten(dynamic.FlattenOptions{})
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+}
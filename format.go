@@ -0,0 +1,245 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Compact returns d with insignificant whitespace removed.
+func (d JSON) Compact() (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Indent returns d with each element of an object or array beginning on a
+// new, indented line. See json.Indent for details on prefix and indent.
+func (d JSON) Indent(prefix, indent string) (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, d.trimSpace(), prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ColorScheme contains the ANSI escape sequences used to colorize json by
+// Pretty
+type ColorScheme struct {
+	Key    string
+	String string
+	Number string
+	Bool   string
+	Null   string
+	Reset  string
+}
+
+// DefaultColorScheme is used by Pretty when PrettyOptions.Color is true and
+// PrettyOptions.Colors is nil
+var DefaultColorScheme = ColorScheme{
+	Key:    "\x1b[34;1m",
+	String: "\x1b[32m",
+	Number: "\x1b[33m",
+	Bool:   "\x1b[35m",
+	Null:   "\x1b[90m",
+	Reset:  "\x1b[0m",
+}
+
+// PrettyOptions configures Pretty
+type PrettyOptions struct {
+	// Prefix begins each line
+	Prefix string
+	// Indent is used for each level of nesting. Defaults to two spaces.
+	Indent string
+	// SortKeys sorts the members of objects by key
+	SortKeys bool
+	// Width is the maximum line width for which an array is kept on a single
+	// line. Arrays are always expanded if Width is 0.
+	Width int
+	// Color wraps keys and scalar values in the ANSI escape sequences of
+	// DefaultColorScheme
+	Color bool
+	// Colors, if set, wraps keys and scalar values in its ANSI escape
+	// sequences rather than those of DefaultColorScheme, regardless of Color.
+	Colors *ColorScheme
+}
+
+// Pretty returns d formatted for humans per opts. Scalar values are copied
+// as they appear in d; only the keys of objects are decoded, and only if
+// opts.SortKeys is set.
+func (d JSON) Pretty(opts PrettyOptions) (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+	p := &prettyPrinter{opts: opts, buf: append([]byte{}, opts.Prefix...)}
+	if opts.Colors != nil {
+		c := *opts.Colors
+		p.colors = &c
+	} else if opts.Color {
+		c := DefaultColorScheme
+		p.colors = &c
+	}
+	if err := p.value(d.trimSpace(), 0, len(opts.Prefix)); err != nil {
+		return nil, err
+	}
+	return p.buf, nil
+}
+
+type prettyPrinter struct {
+	opts PrettyOptions
+	// colors is nil if the output is not colorized
+	colors *ColorScheme
+	buf    []byte
+}
+
+func (p *prettyPrinter) newline(depth int) {
+	p.buf = append(p.buf, '\n')
+	p.buf = append(p.buf, p.opts.Prefix...)
+	for i := 0; i < depth; i++ {
+		p.buf = append(p.buf, p.opts.Indent...)
+	}
+}
+
+// scheme returns the colors of the output, which are empty if it is not
+// colorized
+func (p *prettyPrinter) scheme() ColorScheme {
+	if p.colors == nil {
+		return ColorScheme{}
+	}
+	return *p.colors
+}
+
+func (p *prettyPrinter) colored(color string, v []byte) {
+	if p.colors == nil {
+		p.buf = append(p.buf, v...)
+		return
+	}
+	p.buf = append(p.buf, color...)
+	p.buf = append(p.buf, v...)
+	p.buf = append(p.buf, p.colors.Reset...)
+}
+
+func (p *prettyPrinter) scalar(v JSON) {
+	c := p.scheme()
+	switch v.Kind() {
+	case KindString:
+		p.colored(c.String, v)
+	case KindNumber:
+		p.colored(c.Number, v)
+	case KindBool:
+		p.colored(c.Bool, v)
+	default:
+		p.colored(c.Null, v)
+	}
+}
+
+// value writes v; column is the width of the line thus far
+func (p *prettyPrinter) value(v JSON, depth int, column int) error {
+	kind := v.Kind()
+	if kind != KindObject && kind != KindArray {
+		p.scalar(v)
+		return nil
+	}
+	c, err := scanContainer(v, 0)
+	if err != nil {
+		return err
+	}
+	if kind == KindArray {
+		return p.array(v, c, depth, column)
+	}
+	return p.object(v, c, depth)
+}
+
+func (p *prettyPrinter) array(v JSON, c container, depth int, column int) error {
+	if len(c.members) == 0 {
+		p.buf = append(p.buf, '[', ']')
+		return nil
+	}
+	if p.opts.Width > 0 {
+		if fitsInline(v, c, column, p.opts.Width) {
+			p.buf = append(p.buf, '[')
+			for i, m := range c.members {
+				if i > 0 {
+					p.buf = append(p.buf, ',', ' ')
+				}
+				p.scalar(v[m.value.start:m.value.end])
+			}
+			p.buf = append(p.buf, ']')
+			return nil
+		}
+	}
+	p.buf = append(p.buf, '[')
+	inner := len(p.opts.Prefix) + (depth+1)*len(p.opts.Indent)
+	for i, m := range c.members {
+		if i > 0 {
+			p.buf = append(p.buf, ',')
+		}
+		p.newline(depth + 1)
+		if err := p.value(v[m.value.start:m.value.end], depth+1, inner); err != nil {
+			return err
+		}
+	}
+	p.newline(depth)
+	p.buf = append(p.buf, ']')
+	return nil
+}
+
+// fitsInline reports whether the array, rendered on a single line starting at
+// column, fits within width. Only arrays of scalar values are inlined.
+func fitsInline(v JSON, c container, column, width int) bool {
+	w := column + 2 + (len(c.members)-1)*2
+	for _, m := range c.members {
+		switch v[m.value.start] {
+		case '{', '[':
+			return false
+		}
+		w += m.value.end - m.value.start
+		if w > width {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *prettyPrinter) object(v JSON, c container, depth int) error {
+	if len(c.members) == 0 {
+		p.buf = append(p.buf, '{', '}')
+		return nil
+	}
+	members := c.members
+	if p.opts.SortKeys {
+		members = append([]member{}, members...)
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].key < members[j].key
+		})
+	}
+	p.buf = append(p.buf, '{')
+	for i, m := range members {
+		if i > 0 {
+			p.buf = append(p.buf, ',')
+		}
+		p.newline(depth + 1)
+		key := v[m.span.start:m.value.start]
+		key = key[:bytes.LastIndexByte(key, ':')].trimSpace()
+		p.colored(p.scheme().Key, key)
+		p.buf = append(p.buf, ':', ' ')
+		column := len(p.opts.Prefix) + (depth+1)*len(p.opts.Indent) + len(key) + 2
+		if err := p.value(v[m.value.start:m.value.end], depth+1, column); err != nil {
+			return err
+		}
+	}
+	p.newline(depth)
+	p.buf = append(p.buf, '}')
+	return nil
+}
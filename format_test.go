@@ -0,0 +1,83 @@
+package dynamic_test
+
+import (
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONCompactAndIndent(t *testing.T) {
+	assert := require.New(t)
+	d := dynamic.JSON(" { \"a\" : [ 1 , 2 ],\n \"b\": \"c d\" } ")
+	res, err := d.Compact()
+	assert.NoError(err)
+	assert.Equal(`{"a":[1,2],"b":"c d"}`, string(res))
+
+	res, err = d.Indent("", "\t")
+	assert.NoError(err)
+	assert.Equal("{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t],\n\t\"b\": \"c d\"\n}", string(res))
+
+	_, err = dynamic.JSON(`{"a":`).Compact()
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+	_, err = dynamic.JSON(`[1 2]`).Indent("", "  ")
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+}
+
+func TestJSONPretty(t *testing.T) {
+	assert := require.New(t)
+	d := dynamic.JSON(`{"b":[1,2,3],"a":{"d":[],"c":[{"e":null}, "a long string value"]},"f":{}}`)
+
+	res, err := d.Pretty(dynamic.PrettyOptions{})
+	assert.NoError(err)
+	assert.Equal(`{
+  "b": [
+    1,
+    2,
+    3
+  ],
+  "a": {
+    "d": [],
+    "c": [
+      {
+        "e": null
+      },
+      "a long string value"
+    ]
+  },
+  "f": {}
+}`, string(res))
+
+	res, err = d.Pretty(dynamic.PrettyOptions{SortKeys: true, Width: 20, Indent: "    "})
+	assert.NoError(err)
+	assert.Equal(`{
+    "a": {
+        "c": [
+            {
+                "e": null
+            },
+            "a long string value"
+        ],
+        "d": []
+    },
+    "b": [1, 2, 3],
+    "f": {}
+}`, string(res))
+
+	res, err = dynamic.JSON(`{"k":[true,"s",1,null]}`).Pretty(dynamic.PrettyOptions{Width: 80, Color: true})
+	assert.NoError(err)
+	c := dynamic.DefaultColorScheme
+	assert.Equal("{\n  "+c.Key+`"k"`+c.Reset+": ["+
+		c.Bool+"true"+c.Reset+", "+
+		c.String+`"s"`+c.Reset+", "+
+		c.Number+"1"+c.Reset+", "+
+		c.Null+"null"+c.Reset+"]\n}", string(res))
+
+	scheme := dynamic.ColorScheme{Key: "<k>", String: "<s>", Reset: "</>"}
+	res, err = dynamic.JSON(`{"k":"v","n":1}`).Pretty(dynamic.PrettyOptions{Colors: &scheme})
+	assert.NoError(err)
+	assert.Equal("{\n  <k>\"k\"</>: <s>\"v\"</>,\n  <k>\"n\"</>: 1</>\n}", string(res))
+
+	_, err = dynamic.JSON(`[1,`).Pretty(dynamic.PrettyOptions{})
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+}
@@ -4,7 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"unicode"
+	"fmt"
+	"time"
 )
 
 type JSON []byte
@@ -27,74 +28,197 @@ func (d *JSON) UnmarshalJSON(data []byte) error {
 	*d = append((*d)[0:0], data...)
 	return nil
 }
-func (d JSON) IsObject() bool {
-	for _, v := range d {
-		if !unicode.IsSpace(rune(v)) {
-			return v == '{'
-		}
+
+// Validate fully scans d, reporting a *SyntaxError for the first violation of
+// RFC 8259 it encounters. The error carries the byte offset, line and column
+// of the offending token.
+func (d JSON) Validate() error {
+	return newScanner(d).scanDocument()
+}
+
+// Valid reports whether d is valid json as described by RFC 8259.
+func (d JSON) Valid() bool {
+	return d.Validate() == nil
+}
+
+// Kind reports the Kind of d by scanning its first token. Leading and
+// trailing whitespace is ignored.
+//
+// Scalar values (null, booleans, numbers and strings) are scanned in full and
+// must not be followed by anything other than whitespace. Objects and arrays
+// are reported as such if their opening and closing delimiters match; their
+// contents are not scanned. Use Validate to verify the entire document.
+func (d JSON) Kind() Kind {
+	s := newScanner(d)
+	s.skipSpace()
+	var k Kind
+	var err error
+	switch c := s.peek(); {
+	case c == '{':
+		return d.delimitedKind('}', KindObject)
+	case c == '[':
+		return d.delimitedKind(']', KindArray)
+	case c == '"':
+		k, err = KindString, s.scanString()
+	case c == '-' || isDigit(c):
+		k, err = KindNumber, s.scanNumber()
+	case c == 't':
+		k, err = KindBool, s.scanLiteral("true")
+	case c == 'f':
+		k, err = KindBool, s.scanLiteral("false")
+	case c == 'n':
+		k, err = KindNull, s.scanLiteral("null")
+	default:
+		return KindInvalid
 	}
-	return false
+	if err != nil {
+		return KindInvalid
+	}
+	s.skipSpace()
+	if !s.eof() {
+		return KindInvalid
+	}
+	return k
 }
 
+func (d JSON) delimitedKind(closing byte, k Kind) Kind {
+	t := d.trimSpace()
+	if len(t) < 2 || t[len(t)-1] != closing {
+		return KindInvalid
+	}
+	return k
+}
+
+// trimSpace returns d without leading or trailing json whitespace
+func (d JSON) trimSpace() JSON {
+	start, end := 0, len(d)
+	for start < end && isSpace(d[start]) {
+		start++
+	}
+	for end > start && isSpace(d[end-1]) {
+		end--
+	}
+	return d[start:end]
+}
+
+// IsObject reports whether d is a json object. It does not check whether the
+// contents of the object are malformed.
+func (d JSON) IsObject() bool {
+	return d.Kind() == KindObject
+}
+
+// IsEmptyObject reports whether d is a json object without any members.
 func (d JSON) IsEmptyObject() bool {
-	return d.IsObject() && len(d) == 2
+	return d.IsObject() && d.isEmptyDelimited()
 }
 
+// IsEmptyArray reports whether d is a json array without any elements.
 func (d JSON) IsEmptyArray() bool {
-	if !d.IsArray() {
-		return false
-	}
-	count := 0
-	for _, v := range d {
-		if !unicode.IsSpace(rune(v)) {
-			count += 1
-			if count > 2 {
-				return false
-			}
-		}
-	}
-	return count == 2
+	return d.IsArray() && d.isEmptyDelimited()
 }
 
-// IsArray reports whether the data is a json array. It does not check whether
-// the json is malformed.
+// isEmptyDelimited reports whether there is nothing but whitespace between
+// the opening and closing delimiters of d
+func (d JSON) isEmptyDelimited() bool {
+	t := d.trimSpace()
+	return len(t[1:len(t)-1].trimSpace()) == 0
+}
+
+// IsArray reports whether d is a json array. It does not check whether the
+// contents of the array are malformed.
 func (d JSON) IsArray() bool {
-	for _, v := range d {
-		if !unicode.IsSpace(rune(v)) {
-			return v == '['
-		}
-	}
-	return false
+	return d.Kind() == KindArray
 }
 
+// IsNull reports whether d is the json literal null
 func (d JSON) IsNull() bool {
-	return bytes.Equal(d, Null)
+	return d.Kind() == KindNull
 }
 
-// IsBool reports true if data appears to be a json boolean value. It is
-// possible that it will report false positives of malformed json.
+// IsBool reports whether d is a json boolean value.
 //
 // IsBool does not parse strings
 func (d JSON) IsBool() bool {
-	return d.IsTrue() || d.IsFalse()
+	return d.Kind() == KindBool
 }
 
-// IsTrue reports true if data appears to be a json boolean value of true. It is
-// possible that it will report false positives of malformed json as it only
-// checks the first character and length.
+// IsTrue reports whether d is the json boolean value true.
 //
 // IsTrue does not parse strings
 func (d JSON) IsTrue() bool {
-	return len(d) == 4 && d[0] == 't'
+	return d.IsBool() && d.trimSpace()[0] == 't'
 }
 
-// IsFalse reports true if data appears to be a json boolean value of false. It is
-// possible that it will report false positives of malformed json as it only
-// checks the first character and length.
+// IsFalse reports whether d is the json boolean value false.
 //
 // IsFalse does not parse strings
 func (d JSON) IsFalse() bool {
-	return len(d) == 5 && d[0] == 'f'
+	return d.IsBool() && d.trimSpace()[0] == 'f'
+}
+
+// EachElement calls fn with the index and value of each element of the json
+// array d as it is scanned, without decoding d in its entirety. If fn returns
+// Done, iteration stops and nil is returned. Any other error returned from fn
+// is passed along.
+//
+// The values passed to fn share their underlying bytes with d. A *SyntaxError
+// is returned if malformed json is encountered.
+func (d JSON) EachElement(fn func(i int, v JSON) error) error {
+	s := newScanner(d)
+	s.skipSpace()
+	if s.peek() != '[' {
+		return fmt.Errorf("%w: json is not an array", ErrInvalidType)
+	}
+	stopped := false
+	err := s.scanElements(func(i int, v span) error {
+		err := fn(i, d[v.start:v.end])
+		stopped = err == Done
+		return err
+	})
+	if err != nil || stopped {
+		return err
+	}
+	return s.expectEOF()
+}
+
+// EachMember calls fn with the key and value of each member of the json
+// object d as it is scanned, without decoding d in its entirety. If fn returns
+// Done, iteration stops and nil is returned. Any other error returned from fn
+// is passed along.
+//
+// The values passed to fn share their underlying bytes with d. A *SyntaxError
+// is returned if malformed json is encountered.
+func (d JSON) EachMember(fn func(key string, v JSON) error) error {
+	s := newScanner(d)
+	s.skipSpace()
+	if s.peek() != '{' {
+		return fmt.Errorf("%w: json is not an object", ErrInvalidType)
+	}
+	stopped := false
+	err := s.scanMembers(func(k span, v span) error {
+		key, err := unquote(d[k.start:k.end])
+		if err != nil {
+			return err
+		}
+		err = fn(key, d[v.start:v.end])
+		stopped = err == Done
+		return err
+	})
+	if err != nil || stopped {
+		return err
+	}
+	return s.expectEOF()
+}
+
+// decode decodes data into generic values, retaining numbers as json.Number
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
 }
 
 func (d JSON) Equal(data []byte) bool {
@@ -113,7 +237,7 @@ func (d JSON) ContainsEscapeRune() bool {
 }
 
 // UnquotedString trims double quotes from the bytes. It does not parse for
-// escaped characters
+// escaped characters; use String to decode them.
 func (d JSON) UnquotedString() string {
 	if len(d) < 2 {
 		return string(d)
@@ -125,22 +249,56 @@ func (d JSON) UnquotedString() string {
 	return string(d)
 }
 
-func (d JSON) IsNumber() bool {
-	if len(d) == 0 {
-		return false
+// String decodes d as a json string. Escape sequences, including UTF-16
+// surrogate pairs, are decoded.
+func (d JSON) String() (string, error) {
+	if k := d.Kind(); k != KindString {
+		return "", fmt.Errorf("%w: json is a %s, not a string", ErrInvalidType, k)
 	}
-	switch d[0] {
-	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-':
-		return true
-	default:
-		return false
+	return unquote(d.trimSpace())
+}
+
+// Bool decodes d as a json boolean.
+//
+// Bool does not parse strings
+func (d JSON) Bool() (bool, error) {
+	if k := d.Kind(); k != KindBool {
+		return false, fmt.Errorf("%w: json is a %s, not a bool", ErrInvalidType, k)
 	}
+	return d.IsTrue(), nil
 }
-func (d JSON) IsString() bool {
-	if len(d) == 0 {
-		return false
+
+// Number decodes d as a json number. Integers are stored as either int64 or
+// uint64 so that their precision is retained.
+//
+// Number does not parse strings
+func (d JSON) Number() (Number, error) {
+	if k := d.Kind(); k != KindNumber {
+		return Number{}, fmt.Errorf("%w: json is a %s, not a number", ErrInvalidType, k)
+	}
+	n := Number{}
+	err := n.Parse(string(d.trimSpace()))
+	return n, err
+}
+
+// Time decodes d as a json string and parses it with the first of layouts
+// which succeeds. DefaultTimeLayouts are used if layouts is empty.
+func (d JSON) Time(layouts ...string) (time.Time, error) {
+	s, err := d.String()
+	if err != nil {
+		return time.Time{}, err
 	}
-	return d[0] == '"'
+	return parseTime(s, layouts...)
+}
+
+// IsNumber reports whether d is a valid json number
+func (d JSON) IsNumber() bool {
+	return d.Kind() == KindNumber
+}
+
+// IsString reports whether d is a valid json string
+func (d JSON) IsString() bool {
+	return d.Kind() == KindString
 }
 
 type JSONObject map[string]JSON
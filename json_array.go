@@ -0,0 +1,121 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ElementError is returned when an element of a JSONArray can not be
+// converted
+type ElementError struct {
+	// Index is the position of the element within the JSONArray
+	Index int
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("dynamic: element %d: %v", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// JSONArray is a json array whose elements are kept as raw JSON and decoded
+// only upon access or conversion.
+type JSONArray []JSON
+
+func (a JSONArray) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]JSON(a))
+}
+
+func (a *JSONArray) UnmarshalJSON(data []byte) error {
+	var s []JSON
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	*a = s
+	return nil
+}
+
+// Len returns the number of elements in a
+func (a JSONArray) Len() int {
+	return len(a)
+}
+
+// At returns the element at index i
+func (a JSONArray) At(i int) (JSON, error) {
+	if i < 0 || i >= len(a) {
+		return nil, ErrIndexOutOfBounds
+	}
+	return a[i], nil
+}
+
+// Append adds values to the end of a
+func (a *JSONArray) Append(values ...JSON) {
+	*a = append(*a, values...)
+}
+
+// Slice returns the elements of a from start up to, but not including, end.
+// The returned JSONArray shares its elements with a.
+func (a JSONArray) Slice(start, end int) (JSONArray, error) {
+	if start < 0 || end > len(a) || start > end {
+		return nil, ErrIndexOutOfBounds
+	}
+	return a[start:end], nil
+}
+
+// Strings decodes each element of a as a json string. An *ElementError is
+// returned for the first element which is not a string.
+func (a JSONArray) Strings() (StringOrArrayOfStrings, error) {
+	res := make(StringOrArrayOfStrings, len(a))
+	for i, v := range a {
+		s, err := v.String()
+		if err != nil {
+			return nil, &ElementError{Index: i, Err: err}
+		}
+		res[i] = s
+	}
+	return res, nil
+}
+
+// Numbers decodes each element of a as a json number. Elements which are null
+// result in a Number without a value. An *ElementError is returned for the
+// first element which is neither.
+func (a JSONArray) Numbers() ([]Number, error) {
+	res := make([]Number, len(a))
+	for i, v := range a {
+		if v.IsNull() {
+			continue
+		}
+		n, err := v.Number()
+		if err != nil {
+			return nil, &ElementError{Index: i, Err: err}
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+// Bools decodes each element of a as a json boolean. Elements which are null
+// result in a Bool without a value. An *ElementError is returned for the
+// first element which is neither.
+func (a JSONArray) Bools() ([]Bool, error) {
+	res := make([]Bool, len(a))
+	for i, v := range a {
+		if v.IsNull() {
+			continue
+		}
+		b, err := v.Bool()
+		if err != nil {
+			return nil, &ElementError{Index: i, Err: err}
+		}
+		if b {
+			res[i] = True
+		} else {
+			res[i] = False
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONArray(t *testing.T) {
+	assert := require.New(t)
+	var a dynamic.JSONArray
+	assert.NoError(json.Unmarshal([]byte(`["a", 1, {"b": true}]`), &a))
+	assert.Equal(3, a.Len())
+
+	v, err := a.At(2)
+	assert.NoError(err)
+	assert.Equal(`{"b": true}`, string(v))
+	_, err = a.At(3)
+	assert.ErrorIs(err, dynamic.ErrIndexOutOfBounds)
+
+	a.Append(dynamic.JSON(`null`), dynamic.JSON(`"z"`))
+	s, err := a.Slice(1, 3)
+	assert.NoError(err)
+	assert.Equal(dynamic.JSONArray{dynamic.JSON(`1`), dynamic.JSON(`{"b": true}`)}, s)
+	_, err = a.Slice(3, 6)
+	assert.ErrorIs(err, dynamic.ErrIndexOutOfBounds)
+
+	data, err := json.Marshal(a)
+	assert.NoError(err)
+	assert.Equal(`["a",1,{"b":true},null,"z"]`, string(data))
+}
+
+func TestJSONArrayConversions(t *testing.T) {
+	assert := require.New(t)
+	strs, err := dynamic.JSONArray{dynamic.JSON(`"a"`), dynamic.JSON(`"b\n"`)}.Strings()
+	assert.NoError(err)
+	assert.Equal(dynamic.StringOrArrayOfStrings{"a", "b\n"}, strs)
+
+	nums, err := dynamic.JSONArray{dynamic.JSON(`1`), dynamic.JSON(`null`), dynamic.JSON(`2.5`)}.Numbers()
+	assert.NoError(err)
+	assert.Len(nums, 3)
+	i, ok := nums[0].Int64()
+	assert.True(ok)
+	assert.Equal(int64(1), i)
+	assert.True(nums[1].IsNil())
+	f, ok := nums[2].Float64()
+	assert.True(ok)
+	assert.Equal(2.5, f)
+
+	bools, err := dynamic.JSONArray{dynamic.JSON(`true`), dynamic.JSON(`false`), dynamic.JSON(`null`)}.Bools()
+	assert.NoError(err)
+	assert.Equal(true, bools[0].Value())
+	assert.Equal(false, bools[1].Value())
+	assert.Nil(bools[2].Value())
+
+	_, err = dynamic.JSONArray{dynamic.JSON(`1`), dynamic.JSON(`"2"`)}.Numbers()
+	var eerr *dynamic.ElementError
+	assert.True(errors.As(err, &eerr))
+	assert.Equal(1, eerr.Index)
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+
+	_, err = dynamic.JSONArray{dynamic.JSON(`"a"`), dynamic.JSON(`1`)}.Strings()
+	assert.True(errors.As(err, &eerr))
+	assert.Equal(1, eerr.Index)
+
+	_, err = dynamic.JSONArray{dynamic.JSON(`"true"`)}.Bools()
+	assert.True(errors.As(err, &eerr))
+	assert.Equal(0, eerr.Index)
+}
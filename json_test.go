@@ -2,7 +2,10 @@ package dynamic_test
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/chanced/dynamic"
 	"github.com/stretchr/testify/require"
@@ -76,3 +79,190 @@ func TestJSONOBject(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal(`{"key":{"v":"value"}}`, string(objData))
 }
+
+func TestJSONValidate(t *testing.T) {
+	assert := require.New(t)
+	valid := []string{
+		`null`, `true`, `false`, `0`, `-0.5e+10`, `"aé\"\\"`,
+		` {"a": [1, 2, {"b": null}], "c": "d"} `, `[]`, `{}`,
+	}
+	for _, v := range valid {
+		assert.NoError(dynamic.JSON(v).Validate(), v)
+		assert.True(dynamic.JSON(v).Valid(), v)
+	}
+	invalid := []string{
+		``, `tru`, `nul`, `01`, `1.`, `-`, `"abc`, `"\x"`, "\"\t\"",
+		`{"a" 1}`, `{"a":1,}`, `[1,]`, `[1 2]`, `{1:2}`, `true false`,
+	}
+	for _, v := range invalid {
+		assert.Error(dynamic.JSON(v).Validate(), v)
+		assert.False(dynamic.JSON(v).Valid(), v)
+	}
+
+	err := dynamic.JSON("{\n  \"a\": [1, 2,, 3]\n}").Validate()
+	var serr *dynamic.SyntaxError
+	assert.ErrorAs(err, &serr)
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+	assert.Equal(2, serr.Line)
+	assert.Equal(14, serr.Column)
+	assert.Equal(15, serr.Offset)
+	assert.Equal(",", serr.Token)
+
+	err = dynamic.JSON(`{"a": tru}`).Validate()
+	assert.ErrorAs(err, &serr)
+	assert.Equal("tru", serr.Token)
+	assert.Equal(6, serr.Offset)
+
+	err = dynamic.JSON(strings.Repeat("[", 5000000)).Validate()
+	assert.ErrorAs(err, &serr)
+	assert.Equal(dynamic.MaxNestingDepth, serr.Offset)
+	deep := strings.Repeat(`{"a":`, dynamic.MaxNestingDepth) + "1" + strings.Repeat("}", dynamic.MaxNestingDepth)
+	assert.NoError(dynamic.JSON(deep).Validate())
+	_, err = dynamic.JSON("[" + deep + "]").Compact()
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+}
+
+func TestJSONKind(t *testing.T) {
+	assert := require.New(t)
+	tests := map[string]dynamic.Kind{
+		`null`:       dynamic.KindNull,
+		"  null\n":   dynamic.KindNull,
+		`true`:       dynamic.KindBool,
+		"  true":     dynamic.KindBool,
+		"false \t":   dynamic.KindBool,
+		`34.34`:      dynamic.KindNumber,
+		` -1e3 `:     dynamic.KindNumber,
+		`"str"`:      dynamic.KindString,
+		` "s\"tr" `:  dynamic.KindString,
+		` {"a":1} `:  dynamic.KindObject,
+		"[ ]\n":      dynamic.KindArray,
+		``:           dynamic.KindInvalid,
+		`   `:        dynamic.KindInvalid,
+		`tru`:        dynamic.KindInvalid,
+		`truee`:      dynamic.KindInvalid,
+		`nul`:        dynamic.KindInvalid,
+		`"str`:       dynamic.KindInvalid,
+		`1.`:         dynamic.KindInvalid,
+		`-`:          dynamic.KindInvalid,
+		`true false`: dynamic.KindInvalid,
+		`{"a":1`:     dynamic.KindInvalid,
+		`[`:          dynamic.KindInvalid,
+	}
+	for input, expected := range tests {
+		jd := dynamic.JSON(input)
+		assert.Equal(expected, jd.Kind(), "%q", input)
+		assert.Equal(expected == dynamic.KindNull, jd.IsNull(), "%q", input)
+		assert.Equal(expected == dynamic.KindBool, jd.IsBool(), "%q", input)
+		assert.Equal(expected == dynamic.KindNumber, jd.IsNumber(), "%q", input)
+		assert.Equal(expected == dynamic.KindString, jd.IsString(), "%q", input)
+		assert.Equal(expected == dynamic.KindObject, jd.IsObject(), "%q", input)
+		assert.Equal(expected == dynamic.KindArray, jd.IsArray(), "%q", input)
+	}
+	assert.True(dynamic.JSON("  true ").IsTrue())
+	assert.False(dynamic.JSON("  true ").IsFalse())
+	assert.True(dynamic.JSON(" false").IsFalse())
+	assert.True(dynamic.JSON(" { } ").IsEmptyObject())
+	assert.True(dynamic.JSON("[\n]").IsEmptyArray())
+}
+
+func TestJSONEachElement(t *testing.T) {
+	assert := require.New(t)
+	var values []string
+	err := dynamic.JSON(` [1, "two", {"three": 3}, [4]] `).EachElement(func(i int, v dynamic.JSON) error {
+		assert.Equal(len(values), i)
+		values = append(values, string(v))
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{`1`, `"two"`, `{"three": 3}`, `[4]`}, values)
+
+	values = nil
+	err = dynamic.JSON(`[1, 2, 3, oops]`).EachElement(func(i int, v dynamic.JSON) error {
+		values = append(values, string(v))
+		if i == 1 {
+			return dynamic.Done
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{`1`, `2`}, values)
+
+	err = dynamic.JSON(`[1, 2, 3, oops]`).EachElement(func(i int, v dynamic.JSON) error { return nil })
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+
+	err = dynamic.JSON(`[1, 2]`).EachElement(func(i int, v dynamic.JSON) error { return errors.New("some error") })
+	assert.EqualError(err, "some error")
+
+	err = dynamic.JSON(`{}`).EachElement(func(i int, v dynamic.JSON) error { return nil })
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+}
+
+func TestJSONEachMember(t *testing.T) {
+	assert := require.New(t)
+	var keys, values []string
+	err := dynamic.JSON(`{"a": 1, "b!": [2], "c": null}`).EachMember(func(key string, v dynamic.JSON) error {
+		keys = append(keys, key)
+		values = append(values, string(v))
+		if key == "b!" {
+			return dynamic.Done
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b!"}, keys)
+	assert.Equal([]string{"1", "[2]"}, values)
+
+	err = dynamic.JSON(`{"a": 1} x`).EachMember(func(key string, v dynamic.JSON) error { return nil })
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+
+	err = dynamic.JSON(`[]`).EachMember(func(key string, v dynamic.JSON) error { return nil })
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+}
+
+func TestJSONScalars(t *testing.T) {
+	assert := require.New(t)
+	s, err := dynamic.JSON(` "a\"b\\c\/é😀\n" `).String()
+	assert.NoError(err)
+	assert.Equal("a\"b\\c/é😀\n", s)
+	s, err = dynamic.JSON(`"\ud83d"`).String()
+	assert.NoError(err)
+	assert.Equal("�", s)
+	_, err = dynamic.JSON(`true`).String()
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+
+	b, err := dynamic.JSON(" true ").Bool()
+	assert.NoError(err)
+	assert.True(b)
+	b, err = dynamic.JSON("false").Bool()
+	assert.NoError(err)
+	assert.False(b)
+	_, err = dynamic.JSON(`"true"`).Bool()
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+
+	n, err := dynamic.JSON("18446744073709551615").Number()
+	assert.NoError(err)
+	u, ok := n.Uint64()
+	assert.True(ok)
+	assert.Equal(uint64(18446744073709551615), u)
+	n, err = dynamic.JSON("-9223372036854775808").Number()
+	assert.NoError(err)
+	i, ok := n.Int64()
+	assert.True(ok)
+	assert.Equal(int64(-9223372036854775808), i)
+	n, err = dynamic.JSON("34.34").Number()
+	assert.NoError(err)
+	f, ok := n.Float64()
+	assert.True(ok)
+	assert.Equal(34.34, f)
+	_, err = dynamic.JSON(`"34"`).Number()
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+
+	tm, err := dynamic.JSON(`"2021-01-02T03:04:05Z"`).Time()
+	assert.NoError(err)
+	assert.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), tm)
+	tm, err = dynamic.JSON(`"2021-01-02"`).Time(time.RFC3339, "2006-01-02")
+	assert.NoError(err)
+	assert.Equal(time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), tm)
+	_, err = dynamic.JSON(`"nope"`).Time()
+	assert.Error(err)
+}
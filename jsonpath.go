@@ -0,0 +1,1093 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidJSONPath is wrapped by errors returned when compiling a malformed
+// JSONPath expression
+var ErrInvalidJSONPath = errors.New("dynamic: invalid jsonpath")
+
+// JSONPath is a compiled JSONPath (RFC 9535) query.
+//
+// Supported are the root and current node identifiers, name and index
+// selectors, wildcards, array slices, descendant segments and filter
+// expressions, including the length, count, match, search and value
+// functions.
+type JSONPath struct {
+	expr  string
+	query *jpQuery
+}
+
+// JSONPathMatch is a node selected by a JSONPath query
+type JSONPathMatch struct {
+	// Path is the normalized path of the node, such as $['a'][0]
+	Path string
+	// Value shares its underlying bytes with the queried document
+	Value JSON
+}
+
+// CompileJSONPath parses expr as a JSONPath (RFC 9535) query.
+func CompileJSONPath(expr string) (*JSONPath, error) {
+	p := &jpParser{expr: expr}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(expr) {
+		return nil, p.errorf("unexpected %q", expr[p.pos:])
+	}
+	if !q.absolute {
+		return nil, p.errorAt(0, "query must begin with '$'")
+	}
+	return &JSONPath{expr: expr, query: q}, nil
+}
+
+// MustCompileJSONPath is like CompileJSONPath but panics if expr can not be
+// compiled.
+func MustCompileJSONPath(expr string) *JSONPath {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Query returns the nodes of d selected by p, in order, along with their
+// normalized paths.
+func (p *JSONPath) Query(d JSON) ([]JSONPathMatch, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	root := jpNode{value: d.trimSpace()}
+	nodes := p.query.eval(root, root)
+	matches := make([]JSONPathMatch, len(nodes))
+	for i, n := range nodes {
+		matches[i] = JSONPathMatch{Path: n.normalizedPath(), Value: n.value}
+	}
+	return matches, nil
+}
+
+// Values returns the values of the nodes of d selected by p.
+func (p *JSONPath) Values(d JSON) ([]JSON, error) {
+	matches, err := p.Query(d)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]JSON, len(matches))
+	for i, m := range matches {
+		values[i] = m.Value
+	}
+	return values, nil
+}
+
+// JSONPath compiles expr and returns the values of d which it selects.
+func (d JSON) JSONPath(expr string) ([]JSON, error) {
+	p, err := CompileJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Values(d)
+}
+
+// jpNode is a value within the queried document and its location. Each
+// element of path is either a string member name or an int array index.
+type jpNode struct {
+	path  []interface{}
+	value JSON
+}
+
+func (n jpNode) child(loc interface{}, value JSON) jpNode {
+	return jpNode{path: append(n.path[:len(n.path):len(n.path)], loc), value: value}
+}
+
+// children returns the member values of an object or the elements of an
+// array, in order.
+func (n jpNode) children() []jpNode {
+	c, err := scanContainer(n.value, 0)
+	if err != nil {
+		return nil
+	}
+	res := make([]jpNode, len(c.members))
+	for i, m := range c.members {
+		v := n.value[m.value.start:m.value.end]
+		if c.kind == KindArray {
+			res[i] = n.child(i, v)
+		} else {
+			res[i] = n.child(m.key, v)
+		}
+	}
+	return res
+}
+
+func (n jpNode) normalizedPath() string {
+	b := []byte{'$'}
+	for _, loc := range n.path {
+		b = append(b, '[')
+		switch l := loc.(type) {
+		case int:
+			b = strconv.AppendInt(b, int64(l), 10)
+		case string:
+			b = appendNormalizedName(b, l)
+		}
+		b = append(b, ']')
+	}
+	return string(b)
+}
+
+func appendNormalizedName(b []byte, name string) []byte {
+	b = append(b, '\'')
+	for _, r := range name {
+		switch r {
+		case '\'':
+			b = append(b, '\\', '\'')
+		case '\\':
+			b = append(b, '\\', '\\')
+		case '\b':
+			b = append(b, '\\', 'b')
+		case '\f':
+			b = append(b, '\\', 'f')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			if r < 0x20 {
+				b = append(b, '\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xF])
+			} else {
+				b = appendRune(b, r)
+			}
+		}
+	}
+	return append(b, '\'')
+}
+
+// jpQuery is a root ($) or relative (@) query made up of segments
+type jpQuery struct {
+	absolute bool
+	segments []jpSegment
+}
+
+func (q *jpQuery) eval(root, current jpNode) []jpNode {
+	nodes := []jpNode{current}
+	if q.absolute {
+		nodes[0] = root
+	}
+	for _, seg := range q.segments {
+		var next []jpNode
+		for _, n := range nodes {
+			next = seg.apply(next, root, n)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// singular reports whether the query can select at most one node
+func (q *jpQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case jpName, jpIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+func (s jpSegment) apply(res []jpNode, root, n jpNode) []jpNode {
+	if s.descendant {
+		return s.applyDescendants(res, root, n)
+	}
+	for _, sel := range s.selectors {
+		res = sel.apply(res, root, n)
+	}
+	return res
+}
+
+func (s jpSegment) applyDescendants(res []jpNode, root, n jpNode) []jpNode {
+	for _, sel := range s.selectors {
+		res = sel.apply(res, root, n)
+	}
+	for _, c := range n.children() {
+		res = s.applyDescendants(res, root, c)
+	}
+	return res
+}
+
+type jpSelector interface {
+	apply(res []jpNode, root, n jpNode) []jpNode
+}
+
+type jpName string
+
+func (s jpName) apply(res []jpNode, root, n jpNode) []jpNode {
+	if !n.value.IsObject() {
+		return res
+	}
+	c, err := scanContainer(n.value, 0)
+	if err != nil {
+		return res
+	}
+	if i, ok := c.find(string(s)); ok {
+		m := c.members[i]
+		res = append(res, n.child(m.key, n.value[m.value.start:m.value.end]))
+	}
+	return res
+}
+
+type jpWildcard struct{}
+
+func (jpWildcard) apply(res []jpNode, root, n jpNode) []jpNode {
+	return append(res, n.children()...)
+}
+
+type jpIndex int
+
+func (s jpIndex) apply(res []jpNode, root, n jpNode) []jpNode {
+	if !n.value.IsArray() {
+		return res
+	}
+	children := n.children()
+	i := int(s)
+	if i < 0 {
+		i += len(children)
+	}
+	if i >= 0 && i < len(children) {
+		res = append(res, children[i])
+	}
+	return res
+}
+
+type jpSlice struct {
+	start, end *int
+	step       int
+}
+
+func (s jpSlice) apply(res []jpNode, root, n jpNode) []jpNode {
+	if !n.value.IsArray() || s.step == 0 {
+		return res
+	}
+	children := n.children()
+	l := len(children)
+	normalize := func(i int) int {
+		if i < 0 {
+			return l + i
+		}
+		return i
+	}
+	clamp := func(i, lower, upper int) int {
+		if i < lower {
+			return lower
+		}
+		if i > upper {
+			return upper
+		}
+		return i
+	}
+	if s.step > 0 {
+		lower, upper := 0, l
+		if s.start != nil {
+			lower = clamp(normalize(*s.start), 0, l)
+		}
+		if s.end != nil {
+			upper = clamp(normalize(*s.end), 0, l)
+		}
+		for i := lower; i < upper; i += s.step {
+			res = append(res, children[i])
+		}
+		return res
+	}
+	upper, lower := l-1, -1
+	if s.start != nil {
+		upper = clamp(normalize(*s.start), -1, l-1)
+	}
+	if s.end != nil {
+		lower = clamp(normalize(*s.end), -1, l-1)
+	}
+	for i := upper; lower < i; i += s.step {
+		res = append(res, children[i])
+	}
+	return res
+}
+
+type jpFilter struct {
+	expr jpExpr
+}
+
+func (s jpFilter) apply(res []jpNode, root, n jpNode) []jpNode {
+	for _, c := range n.children() {
+		if s.expr.test(root, c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// jpExpr is a logical expression of a filter
+type jpExpr interface {
+	test(root, current jpNode) bool
+}
+
+type jpOr []jpExpr
+
+func (e jpOr) test(root, current jpNode) bool {
+	for _, x := range e {
+		if x.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+type jpAnd []jpExpr
+
+func (e jpAnd) test(root, current jpNode) bool {
+	for _, x := range e {
+		if !x.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+type jpNot struct {
+	expr jpExpr
+}
+
+func (e jpNot) test(root, current jpNode) bool {
+	return !e.expr.test(root, current)
+}
+
+// jpExists tests whether a query selects any nodes
+type jpExists struct {
+	query *jpQuery
+}
+
+func (e jpExists) test(root, current jpNode) bool {
+	return len(e.query.eval(root, current)) > 0
+}
+
+// jpLogicalFunc tests the result of a function returning a logical value
+type jpLogicalFunc struct {
+	fn *jpFunction
+}
+
+func (e jpLogicalFunc) test(root, current jpNode) bool {
+	v, ok := e.fn.value(root, current)
+	return ok && v.IsTrue()
+}
+
+type jpComparison struct {
+	left, right jpOperand
+	op          string
+}
+
+func (e jpComparison) test(root, current jpNode) bool {
+	l, lok := e.left.value(root, current)
+	r, rok := e.right.value(root, current)
+	switch e.op {
+	case "==":
+		return jpEqual(l, lok, r, rok)
+	case "!=":
+		return !jpEqual(l, lok, r, rok)
+	case "<":
+		return jpLess(l, lok, r, rok)
+	case "<=":
+		return jpLess(l, lok, r, rok) || jpEqual(l, lok, r, rok)
+	case ">":
+		return jpLess(r, rok, l, lok)
+	case ">=":
+		return jpLess(r, rok, l, lok) || jpEqual(l, lok, r, rok)
+	}
+	return false
+}
+
+func jpEqual(a JSON, aok bool, b JSON, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	ak, bk := a.Kind(), b.Kind()
+	if ak != bk {
+		return false
+	}
+	switch ak {
+	case KindNumber:
+		af, _ := strconv.ParseFloat(string(a), 64)
+		bf, _ := strconv.ParseFloat(string(b), 64)
+		return af == bf
+	case KindString:
+		as, _ := unquote(a)
+		bs, _ := unquote(b)
+		return as == bs
+	default:
+		eq, err := equalJSON(a, b)
+		return err == nil && eq
+	}
+}
+
+func jpLess(a JSON, aok bool, b JSON, bok bool) bool {
+	if !aok || !bok {
+		return false
+	}
+	ak, bk := a.Kind(), b.Kind()
+	if ak != bk {
+		return false
+	}
+	switch ak {
+	case KindNumber:
+		af, _ := strconv.ParseFloat(string(a), 64)
+		bf, _ := strconv.ParseFloat(string(b), 64)
+		return af < bf
+	case KindString:
+		as, _ := unquote(a)
+		bs, _ := unquote(b)
+		return as < bs
+	}
+	return false
+}
+
+// jpOperand is a literal, a query or a function. Only one is set.
+type jpOperand struct {
+	literal JSON
+	query   *jpQuery
+	fn      *jpFunction
+}
+
+// value returns the value of the operand. The result is false if the operand
+// evaluates to Nothing.
+func (o jpOperand) value(root, current jpNode) (JSON, bool) {
+	switch {
+	case o.literal != nil:
+		return o.literal, true
+	case o.query != nil:
+		nodes := o.query.eval(root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].value, true
+	default:
+		return o.fn.value(root, current)
+	}
+}
+
+type jpFunctionType int
+
+const (
+	jpValueType jpFunctionType = iota
+	jpLogicalType
+	jpNodesType
+)
+
+type jpFunction struct {
+	name string
+	args []jpOperand
+	re   *regexp.Regexp
+}
+
+func (f *jpFunction) resultType() jpFunctionType {
+	switch f.name {
+	case "match", "search":
+		return jpLogicalType
+	default:
+		return jpValueType
+	}
+}
+
+func (f *jpFunction) value(root, current jpNode) (JSON, bool) {
+	switch f.name {
+	case "length":
+		v, ok := f.args[0].value(root, current)
+		if !ok {
+			return nil, false
+		}
+		var n int
+		switch v.Kind() {
+		case KindString:
+			s, _ := unquote(v)
+			n = utf8.RuneCountInString(s)
+		case KindArray, KindObject:
+			c, err := scanContainer(v.trimSpace(), 0)
+			if err != nil {
+				return nil, false
+			}
+			n = len(c.members)
+		default:
+			return nil, false
+		}
+		return JSON(strconv.Itoa(n)), true
+	case "count":
+		return JSON(strconv.Itoa(len(f.args[0].query.eval(root, current)))), true
+	case "value":
+		nodes := f.args[0].query.eval(root, current)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0].value, true
+	case "match", "search":
+		v, ok := f.args[0].value(root, current)
+		if !ok || !v.IsString() {
+			return JSON("false"), true
+		}
+		s, _ := unquote(v)
+		re := f.re
+		if re == nil {
+			p, ok := f.args[1].value(root, current)
+			if !ok || !p.IsString() {
+				return JSON("false"), true
+			}
+			pattern, _ := unquote(p)
+			var err error
+			if re, err = compileIRegexp(pattern, f.name == "match"); err != nil {
+				return JSON("false"), true
+			}
+		}
+		return JSON(strconv.FormatBool(re.MatchString(s))), true
+	}
+	return nil, false
+}
+
+// compileIRegexp compiles an I-Regexp (RFC 9485) pattern. In I-Regexp, "."
+// matches any character other than line feeds and carriage returns.
+func compileIRegexp(pattern string, anchored bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(pattern[i])
+			continue
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if anchored {
+		return regexp.Compile(`^(?:` + b.String() + `)$`)
+	}
+	return regexp.Compile(b.String())
+}
+
+// jpParser parses JSONPath expressions
+type jpParser struct {
+	expr string
+	pos  int
+}
+
+func (p *jpParser) errorAt(pos int, msg string) error {
+	return fmt.Errorf("%w: %s at offset %d of %q", ErrInvalidJSONPath, msg, pos, p.expr)
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos >= len(p.expr) {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+func (p *jpParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.expr[p.pos:], s)
+}
+
+func (p *jpParser) skipSpace() {
+	for p.pos < len(p.expr) && isSpace(p.expr[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *jpParser) parseQuery() (*jpQuery, error) {
+	q := &jpQuery{}
+	switch p.peek() {
+	case '$':
+		q.absolute = true
+	case '@':
+	default:
+		return nil, p.errorf("expected '$' or '@'")
+	}
+	p.pos++
+	for {
+		start := p.pos
+		p.skipSpace()
+		switch {
+		case p.hasPrefix(".."):
+			p.pos += 2
+			seg, err := p.parseSegmentAfterDot(true)
+			if err != nil {
+				return nil, err
+			}
+			q.segments = append(q.segments, seg)
+		case p.peek() == '.':
+			p.pos++
+			seg, err := p.parseSegmentAfterDot(false)
+			if err != nil {
+				return nil, err
+			}
+			q.segments = append(q.segments, seg)
+		case p.peek() == '[':
+			sels, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			q.segments = append(q.segments, jpSegment{selectors: sels})
+		default:
+			p.pos = start
+			return q, nil
+		}
+	}
+}
+
+func (p *jpParser) parseSegmentAfterDot(descendant bool) (jpSegment, error) {
+	seg := jpSegment{descendant: descendant}
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		seg.selectors = []jpSelector{jpWildcard{}}
+	case c == '[' && descendant:
+		sels, err := p.parseBracket()
+		if err != nil {
+			return seg, err
+		}
+		seg.selectors = sels
+	default:
+		name, ok := p.parseMemberName()
+		if !ok {
+			return seg, p.errorf("expected member name")
+		}
+		seg.selectors = []jpSelector{jpName(name)}
+	}
+	return seg, nil
+}
+
+func isNameFirst(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || r >= 0x80
+}
+
+func (p *jpParser) parseMemberName() (string, bool) {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !isNameFirst(r) && !(p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	return p.expr[start:p.pos], p.pos > start
+}
+
+func (p *jpParser) parseBracket() ([]jpSelector, error) {
+	p.pos++ // [
+	var sels []jpSelector
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return sels, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseStringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := unquote(s)
+		return jpName(name), nil
+	case c == '*':
+		p.pos++
+		return jpWildcard{}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return jpFilter{expr: expr}, nil
+	case c == '-' || isDigit(c) || c == ':':
+		return p.parseIndexOrSlice()
+	default:
+		return nil, p.errorf("invalid selector")
+	}
+}
+
+func (p *jpParser) parseInt() (int, bool) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	s := p.expr[start:p.pos]
+	if p.pos == digits || (p.expr[digits] == '0' && (p.pos-digits > 1 || digits > start)) {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || i > maxJSONInt || i < smallestJSONInt || int64(int(i)) != i {
+		return 0, false
+	}
+	return int(i), true
+}
+
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var bounds [3]*int
+	n := 0
+	for {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || isDigit(c) {
+			i, ok := p.parseInt()
+			if !ok {
+				return nil, p.errorf("invalid integer")
+			}
+			bounds[n] = &i
+			p.skipSpace()
+		}
+		if p.peek() != ':' || n == 2 {
+			break
+		}
+		p.pos++
+		n++
+	}
+	if n == 0 {
+		if bounds[0] == nil {
+			return nil, p.errorf("expected integer")
+		}
+		return jpIndex(*bounds[0]), nil
+	}
+	s := jpSlice{start: bounds[0], end: bounds[1], step: 1}
+	if bounds[2] != nil {
+		s.step = *bounds[2]
+	}
+	return s, nil
+}
+
+// parseStringLiteral parses a single or double quoted string, returning it
+// as a json string
+func (p *jpParser) parseStringLiteral() (JSON, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+	b := []byte{'"'}
+	for {
+		if p.pos >= len(p.expr) {
+			return nil, p.errorAt(start, "unterminated string")
+		}
+		c := p.expr[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			b = append(b, '"')
+			if err := newScanner(b).scanDocument(); err != nil {
+				return nil, p.errorAt(start, "invalid string")
+			}
+			return b, nil
+		case c == '\\':
+			if p.pos+1 >= len(p.expr) {
+				return nil, p.errorAt(start, "unterminated string")
+			}
+			next := p.expr[p.pos+1]
+			switch {
+			case next == '\'' && quote == '\'':
+				b = append(b, '\'')
+			case next == '\'':
+				return nil, p.errorf("invalid escape")
+			default:
+				b = append(b, c, next)
+			}
+			p.pos += 2
+		case c == '"':
+			b = append(b, '\\', '"')
+			p.pos++
+		default:
+			b = append(b, c)
+			p.pos++
+		}
+	}
+}
+
+func (p *jpParser) parseOr() (jpExpr, error) {
+	var or jpOr
+	for {
+		and, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, and)
+		p.skipSpace()
+		if !p.hasPrefix("||") {
+			break
+		}
+		p.pos += 2
+		p.skipSpace()
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *jpParser) parseAnd() (jpExpr, error) {
+	var and jpAnd
+	for {
+		e, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, e)
+		p.skipSpace()
+		if !p.hasPrefix("&&") {
+			break
+		}
+		p.pos += 2
+		p.skipSpace()
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *jpParser) parseBasic() (jpExpr, error) {
+	switch p.peek() {
+	case '!':
+		p.pos++
+		p.skipSpace()
+		if p.peek() == '(' {
+			e, err := p.parseParen()
+			if err != nil {
+				return nil, err
+			}
+			return jpNot{expr: e}, nil
+		}
+		start := p.pos
+		o, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		e, err := p.testExpr(start, o)
+		if err != nil {
+			return nil, err
+		}
+		return jpNot{expr: e}, nil
+	case '(':
+		return p.parseParen()
+	}
+	start := p.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	op := p.comparisonOperator()
+	if op == "" {
+		return p.testExpr(start, left)
+	}
+	if err := p.checkComparable(start, left); err != nil {
+		return nil, err
+	}
+	p.pos += len(op)
+	p.skipSpace()
+	start = p.pos
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkComparable(start, right); err != nil {
+		return nil, err
+	}
+	return jpComparison{left: left, right: right, op: op}, nil
+}
+
+func (p *jpParser) parseParen() (jpExpr, error) {
+	p.pos++ // (
+	p.skipSpace()
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf("expected ')'")
+	}
+	p.pos++
+	return e, nil
+}
+
+func (p *jpParser) comparisonOperator() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *jpParser) testExpr(start int, o jpOperand) (jpExpr, error) {
+	switch {
+	case o.query != nil:
+		return jpExists{query: o.query}, nil
+	case o.fn != nil && o.fn.resultType() == jpLogicalType:
+		return jpLogicalFunc{fn: o.fn}, nil
+	default:
+		return nil, p.errorAt(start, "expected a query or a logical function")
+	}
+}
+
+func (p *jpParser) checkComparable(start int, o jpOperand) error {
+	switch {
+	case o.query != nil && !o.query.singular():
+		return p.errorAt(start, "queries which are compared must be singular")
+	case o.fn != nil && o.fn.resultType() != jpValueType:
+		return p.errorAt(start, "function result can not be compared")
+	}
+	return nil
+}
+
+func (p *jpParser) parseOperand() (jpOperand, error) {
+	switch c := p.peek(); {
+	case c == '$' || c == '@':
+		q, err := p.parseQuery()
+		if err != nil {
+			return jpOperand{}, err
+		}
+		return jpOperand{query: q}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseStringLiteral()
+		if err != nil {
+			return jpOperand{}, err
+		}
+		return jpOperand{literal: s}, nil
+	case c == '-' || isDigit(c):
+		s := newScanner([]byte(p.expr))
+		s.pos = p.pos
+		if err := s.scanNumber(); err != nil {
+			return jpOperand{}, p.errorf("invalid number")
+		}
+		lit := JSON(p.expr[p.pos:s.pos])
+		p.pos = s.pos
+		return jpOperand{literal: lit}, nil
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for c := p.peek(); c >= 'a' && c <= 'z' || c == '_' || isDigit(c); c = p.peek() {
+			p.pos++
+		}
+		name := p.expr[start:p.pos]
+		if p.peek() == '(' {
+			fn, err := p.parseFunction(start, name)
+			if err != nil {
+				return jpOperand{}, err
+			}
+			return jpOperand{fn: fn}, nil
+		}
+		switch name {
+		case "true", "false", "null":
+			return jpOperand{literal: JSON(name)}, nil
+		}
+		return jpOperand{}, p.errorAt(start, "unexpected "+strconv.Quote(name))
+	default:
+		return jpOperand{}, p.errorf("expected a literal, query or function")
+	}
+}
+
+func (p *jpParser) parseFunction(start int, name string) (*jpFunction, error) {
+	fn := &jpFunction{name: name}
+	var arity int
+	switch name {
+	case "length", "count", "value":
+		arity = 1
+	case "match", "search":
+		arity = 2
+	default:
+		return nil, p.errorAt(start, "unknown function "+strconv.Quote(name))
+	}
+	p.pos++ // (
+	for {
+		p.skipSpace()
+		if p.peek() == ')' && len(fn.args) == 0 {
+			break
+		}
+		argStart := p.pos
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case name == "count" || name == "value":
+			if arg.query == nil {
+				return nil, p.errorAt(argStart, name+"() requires a query")
+			}
+		default:
+			if err := p.checkComparable(argStart, arg); err != nil {
+				return nil, err
+			}
+		}
+		fn.args = append(fn.args, arg)
+		p.skipSpace()
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() != ')' {
+		return nil, p.errorf("expected ')'")
+	}
+	p.pos++
+	if len(fn.args) != arity {
+		return nil, p.errorAt(start, fmt.Sprintf("%s() requires %d arguments", name, arity))
+	}
+	if arity == 2 && fn.args[1].literal != nil && fn.args[1].literal.IsString() {
+		pattern, _ := unquote(fn.args[1].literal)
+		re, err := compileIRegexp(pattern, name == "match")
+		if err != nil {
+			return nil, p.errorAt(start, "invalid regular expression")
+		}
+		fn.re = re
+	}
+	return fn, nil
+}
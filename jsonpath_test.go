@@ -0,0 +1,100 @@
+package dynamic_test
+
+import (
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+var bookstore = dynamic.JSON(`{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`)
+
+func jsonStrings(values []dynamic.JSON) []string {
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = string(v)
+	}
+	return res
+}
+
+func TestJSONPath(t *testing.T) {
+	assert := require.New(t)
+	tests := map[string][]string{
+		`$.store.book[*].author`:              {`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`},
+		`$..author`:                           {`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`},
+		`$.store..price`:                      {`8.95`, `12.99`, `8.99`, `22.99`, `399`},
+		`$..book[2].title`:                    {`"Moby Dick"`},
+		`$..book[-1].title`:                   {`"The Lord of the Rings"`},
+		`$..book[0,1].title`:                  {`"Sayings of the Century"`, `"Sword of Honour"`},
+		`$..book[:2].title`:                   {`"Sayings of the Century"`, `"Sword of Honour"`},
+		`$..book[::-2].price`:                 {`22.99`, `12.99`},
+		`$..book[?@.isbn].title`:              {`"Moby Dick"`, `"The Lord of the Rings"`},
+		`$..book[?@.price<10].title`:          {`"Sayings of the Century"`, `"Moby Dick"`},
+		`$..book[?@.price<10 && @.isbn].isbn`: {`"0-553-21311-3"`},
+		`$..book[?!@.isbn].price`:             {`8.95`, `12.99`},
+		`$..book[?(@.price > 20 || @.category == 'reference')].price`: {`8.95`, `22.99`},
+		`$..book[?@.author == $.store.book[1].author].price`:          {`12.99`},
+		`$..book[?match(@.author, 'J.*')].title`:                      {`"The Lord of the Rings"`},
+		`$..book[?search(@.title, 'of')].price`:                       {`8.95`, `12.99`, `22.99`},
+		`$..book[?length(@.title) == 9].price`:                        {`8.99`},
+		`$.store[?count(@.*) == 2].color`:                             {`"red"`},
+		`$.store[?value(@..color) == "red"].price`:                    {`399`},
+		`$.store["bicycle"]['color']`:                                 {`"red"`},
+		`$.nope`:                                                      {},
+		`$.store.book[10]`:                                            {},
+	}
+	for expr, expected := range tests {
+		values, err := bookstore.JSONPath(expr)
+		assert.NoError(err, expr)
+		assert.Equal(expected, jsonStrings(values), expr)
+	}
+
+	matches, err := dynamic.MustCompileJSONPath(`$..book[?@.price > 20]['title','price']`).Query(bookstore)
+	assert.NoError(err)
+	assert.Len(matches, 2)
+	assert.Equal(`$['store']['book'][3]['title']`, matches[0].Path)
+	assert.Equal(`$['store']['book'][3]['price']`, matches[1].Path)
+
+	matches, err = dynamic.MustCompileJSONPath(`$["a'b"]`).Query(dynamic.JSON(`{"a'b":1}`))
+	assert.NoError(err)
+	assert.Equal(`$['a\'b']`, matches[0].Path)
+
+	invalid := []string{
+		``, `store`, `@.a`, `$.`, `$[`, `$[01]`, `$[?@.a == @..b]`, `$[?@.a == 1 ==]`,
+		`$[?foo(@.a)]`, `$[?length(@.a)]`, `$[?1]`, `$['a]`, `$[1:2:3:4]`,
+	}
+	for _, expr := range invalid {
+		_, err := dynamic.CompileJSONPath(expr)
+		assert.ErrorIs(err, dynamic.ErrInvalidJSONPath, expr)
+	}
+}
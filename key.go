@@ -0,0 +1,47 @@
+package dynamic
+
+import "encoding/json"
+
+// Key is a key of a Map whose value is of type T, providing typed access:
+//
+//	var name = dynamic.Key[string]("name")
+//	name.Set(m, "ann")
+//	v, ok := name.Get(m)
+type Key[T any] string
+
+// Get returns the value of k within m and whether it exists, is not null and
+// is of type T. Values of other types are converted by encoding them as json
+// and decoding the result into T, so that, for example, a float64 decoded from
+// json can be read as an int.
+func (k Key[T]) Get(m Map) (T, bool) {
+	var zero T
+	v, ok := m[string(k)]
+	if !ok || v == nil {
+		return zero, false
+	}
+	if raw, ok := asRawJSON(v); ok && raw.IsNull() {
+		return zero, false
+	}
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return zero, false
+	}
+	var t T
+	if err := json.Unmarshal(data, &t); err != nil {
+		return zero, false
+	}
+	return t, true
+}
+
+// Set sets the value of k within m to v
+func (k Key[T]) Set(m Map, v T) {
+	m[string(k)] = v
+}
+
+// Delete removes k from m
+func (k Key[T]) Delete(m Map) {
+	delete(m, string(k))
+}
@@ -0,0 +1,60 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	assert := require.New(t)
+	type address struct {
+		City string `json:"city"`
+	}
+	var (
+		name  = dynamic.Key[string]("name")
+		age   = dynamic.Key[int]("age")
+		addr  = dynamic.Key[address]("address")
+		ratio = dynamic.Key[float64]("ratio")
+	)
+	var m dynamic.Map
+	assert.NoError(json.Unmarshal([]byte(`{"name": "ann", "age": 34, "address": {"city": "Oslo"}, "ratio": "x"}`), &m))
+
+	v, ok := name.Get(m)
+	assert.True(ok)
+	assert.Equal("ann", v)
+
+	a, ok := age.Get(m)
+	assert.True(ok)
+	assert.Equal(34, a)
+
+	ad, ok := addr.Get(m)
+	assert.True(ok)
+	assert.Equal(address{City: "Oslo"}, ad)
+
+	_, ok = ratio.Get(m)
+	assert.False(ok)
+
+	age.Set(m, 35)
+	a, ok = age.Get(m)
+	assert.True(ok)
+	assert.Equal(35, a)
+	assert.Equal(35, m["age"])
+
+	name.Delete(m)
+	_, ok = name.Get(m)
+	assert.False(ok)
+
+	m["age"] = nil
+	_, ok = age.Get(m)
+	assert.False(ok)
+	m["name"] = dynamic.JSON("null")
+	_, ok = name.Get(m)
+	assert.False(ok)
+	m["age"] = 0
+	a, ok = age.Get(m)
+	assert.True(ok)
+	assert.Equal(0, a)
+}
@@ -0,0 +1,259 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrLimitExceeded is wrapped by LimitError
+var ErrLimitExceeded = errors.New("dynamic: limit exceeded")
+
+// LimitError is returned when json exceeds one of the limits of
+// DecodeOptions.
+type LimitError struct {
+	// Limit is the name of the DecodeOptions field which was exceeded
+	Limit string
+	// Max is the value of the limit
+	Max int
+	// Path is the JSON Pointer of the value at which the limit was exceeded
+	Path string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s of %d at %q", ErrLimitExceeded, e.Limit, e.Max, e.Path)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+func (opts DecodeOptions) hasLimits() bool {
+	return opts.MaxBytes > 0 || opts.MaxDepth > 0 || opts.MaxStringLength > 0 ||
+		opts.MaxNumberDigits > 0 || opts.MaxMembers > 0 || opts.MaxElements > 0
+}
+
+func (opts DecodeOptions) maxDepth() int {
+	if opts.MaxDepth <= 0 || opts.MaxDepth > MaxNestingDepth {
+		return MaxNestingDepth
+	}
+	return opts.MaxDepth
+}
+
+// scan scans data, failing with a *LimitError as soon as a limit of opts is
+// exceeded, with a *DuplicateKeyError if opts require it or with a
+// *SyntaxError if data is malformed.
+func (opts DecodeOptions) scan(data []byte) (*limiter, error) {
+	if opts.MaxBytes > 0 && len(data) > opts.MaxBytes {
+		return nil, &LimitError{Limit: "MaxBytes", Max: opts.MaxBytes}
+	}
+	l := &limiter{opts: opts, s: newScanner(data)}
+	if opts.DuplicateKeys != DuplicateKeysLastWins {
+		l.rewrites = map[int]container{}
+	}
+	l.s.skipSpace()
+	if _, err := l.value(nil, 0); err != nil {
+		return nil, err
+	}
+	if err := l.s.expectEOF(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// limiter scans json while enforcing the limits of opts. If the duplicate key
+// policy of opts is not DuplicateKeysLastWins, the containers which must be
+// rewritten to apply it are recorded.
+//
+// The path passed down while scanning is a stack shared by every value; it is
+// only formatted when an error occurs.
+type limiter struct {
+	opts DecodeOptions
+	s    *scanner
+	// rewrites are the objects with duplicate keys and the containers which
+	// contain them, by offset
+	rewrites map[int]container
+}
+
+func (l *limiter) exceeded(limit string, max int, path []string) error {
+	return &LimitError{Limit: limit, Max: max, Path: FormatPointer(path...)}
+}
+
+// value scans the value at the current position, reporting whether it must
+// be rewritten
+func (l *limiter) value(path []string, depth int) (bool, error) {
+	s := l.s
+	switch c := s.peek(); {
+	case c == '{' || c == '[':
+		if max := l.opts.maxDepth(); depth >= max {
+			return false, l.exceeded("MaxDepth", max, path)
+		}
+		if c == '{' {
+			return l.object(path, depth+1)
+		}
+		return l.array(path, depth+1)
+	case c == '"':
+		return false, l.string(path)
+	case c == '-' || isDigit(c):
+		start := s.pos
+		if err := s.scanNumber(); err != nil {
+			return false, err
+		}
+		if l.opts.MaxNumberDigits > 0 {
+			digits := 0
+			for _, b := range s.data[start:s.pos] {
+				if isDigit(b) {
+					digits++
+				}
+			}
+			if digits > l.opts.MaxNumberDigits {
+				return false, l.exceeded("MaxNumberDigits", l.opts.MaxNumberDigits, path)
+			}
+		}
+		return false, nil
+	default:
+		return false, s.scanValue()
+	}
+}
+
+// string scans a string, enforcing MaxStringLength
+func (l *limiter) string(path []string) error {
+	start := l.s.pos
+	if err := l.s.scanString(); err != nil {
+		return err
+	}
+	if l.opts.MaxStringLength > 0 && l.s.pos-start-2 > l.opts.MaxStringLength {
+		return l.exceeded("MaxStringLength", l.opts.MaxStringLength, path)
+	}
+	return nil
+}
+
+func (l *limiter) object(path []string, depth int) (bool, error) {
+	s := l.s
+	c := container{span: span{start: s.pos}, kind: KindObject}
+	s.pos++ // {
+	s.skipSpace()
+	if s.peek() == '}' {
+		s.pos++
+		return false, nil
+	}
+	var seen map[string]bool
+	if l.rewrites != nil {
+		seen = map[string]bool{}
+	}
+	rewrite := false
+	for n := 1; ; n++ {
+		if l.opts.MaxMembers > 0 && n > l.opts.MaxMembers {
+			return false, l.exceeded("MaxMembers", l.opts.MaxMembers, path)
+		}
+		if s.peek() != '"' {
+			if s.eof() {
+				return false, s.unexpected()
+			}
+			return false, s.errorAt(s.pos, "expected string for object key")
+		}
+		start := s.pos
+		if err := s.scanString(); err != nil {
+			return false, err
+		}
+		key, err := unquote(s.data[start:s.pos])
+		if err != nil {
+			return false, err
+		}
+		p := append(path, key)
+		if l.opts.MaxStringLength > 0 && s.pos-start-2 > l.opts.MaxStringLength {
+			return false, l.exceeded("MaxStringLength", l.opts.MaxStringLength, p)
+		}
+		s.skipSpace()
+		if s.peek() != ':' {
+			if s.eof() {
+				return false, s.unexpected()
+			}
+			return false, s.errorAt(s.pos, "expected ':' after object key")
+		}
+		s.pos++
+		s.skipSpace()
+		v := span{start: s.pos}
+		nested, err := l.value(p, depth)
+		if err != nil {
+			return false, err
+		}
+		v.end = s.pos
+		if l.rewrites != nil {
+			if seen[key] {
+				if l.opts.DuplicateKeys == DuplicateKeysError {
+					return false, &DuplicateKeyError{Key: key, Path: FormatPointer(p...)}
+				}
+				nested = true
+			}
+			seen[key] = true
+			rewrite = rewrite || nested
+			c.members = append(c.members, member{key: key, value: v})
+		}
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+			s.skipSpace()
+		case '}':
+			s.pos++
+			return l.record(c, rewrite), nil
+		default:
+			if s.eof() {
+				return false, s.unexpected()
+			}
+			return false, s.errorAt(s.pos, "expected ',' or '}' after object value")
+		}
+	}
+}
+
+func (l *limiter) array(path []string, depth int) (bool, error) {
+	s := l.s
+	c := container{span: span{start: s.pos}, kind: KindArray}
+	s.pos++ // [
+	s.skipSpace()
+	if s.peek() == ']' {
+		s.pos++
+		return false, nil
+	}
+	rewrite := false
+	for i := 0; ; i++ {
+		if l.opts.MaxElements > 0 && i >= l.opts.MaxElements {
+			return false, l.exceeded("MaxElements", l.opts.MaxElements, path)
+		}
+		v := span{start: s.pos}
+		nested, err := l.value(append(path, strconv.Itoa(i)), depth)
+		if err != nil {
+			return false, err
+		}
+		v.end = s.pos
+		if l.rewrites != nil {
+			rewrite = rewrite || nested
+			c.members = append(c.members, member{value: v})
+		}
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+			s.skipSpace()
+		case ']':
+			s.pos++
+			return l.record(c, rewrite), nil
+		default:
+			if s.eof() {
+				return false, s.unexpected()
+			}
+			return false, s.errorAt(s.pos, "expected ',' or ']' after array element")
+		}
+	}
+}
+
+// record records c, which ends at the current position, if it must be
+// rewritten
+func (l *limiter) record(c container, rewrite bool) bool {
+	if rewrite {
+		c.span.end = l.s.pos
+		l.rewrites[c.span.start] = c
+	}
+	return rewrite
+}
@@ -1,3 +1,239 @@
 package dynamic
 
+import (
+	"encoding/json"
+	"errors"
+)
+
 type Map map[string]interface{}
+
+// Pointer returns the value referenced by the JSON Pointer ptr (RFC 6901).
+// Nested Map, map[string]interface{}, JSONObject and []interface{} values are
+// traversed. If a JSON value is encountered, the remainder of ptr is resolved
+// against it and the result is a JSON.
+func (m Map) Pointer(ptr string) (interface{}, error) {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return pointerGet(m, ptr, tokens)
+}
+
+// SetPointer sets the value referenced by the JSON Pointer ptr (RFC 6901).
+// Members of objects are added if they do not exist. Elements of
+// []interface{} can be replaced by index or appended by using "-" or the
+// length of the slice as the final token.
+//
+// The parent of the referenced value must exist.
+func (m Map) SetPointer(ptr string, value interface{}) error {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return &PointerError{Pointer: ptr, Err: ErrInvalidPointer}
+	}
+	_, err = pointerSet(m, ptr, tokens, value)
+	return err
+}
+
+// DeletePointer removes the value referenced by the JSON Pointer ptr (RFC
+// 6901).
+func (m Map) DeletePointer(ptr string) error {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return &PointerError{Pointer: ptr, Err: ErrInvalidPointer}
+	}
+	_, err = pointerDelete(m, ptr, tokens)
+	return err
+}
+
+// asObject returns v as a map[string]interface{} if it is a Map or a
+// map[string]interface{}
+func asObject(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case Map:
+		return t, t != nil
+	case map[string]interface{}:
+		return t, t != nil
+	default:
+		return nil, false
+	}
+}
+
+// asRawJSON returns v as JSON if it is JSON or a json.RawMessage
+func asRawJSON(v interface{}) (JSON, bool) {
+	switch t := v.(type) {
+	case JSON:
+		return t, true
+	case json.RawMessage:
+		return JSON(t), true
+	default:
+		return nil, false
+	}
+}
+
+// rawPointerError updates the pointer of err, if it is a *PointerError, to ptr
+// as JSON values only see the remainder of the original pointer.
+func rawPointerError(err error, ptr string) error {
+	var perr *PointerError
+	if errors.As(err, &perr) {
+		perr.Pointer = ptr
+	}
+	return err
+}
+
+func pointerGet(v interface{}, ptr string, tokens []string) (interface{}, error) {
+	for i, token := range tokens {
+		if raw, ok := asRawJSON(v); ok {
+			res, err := raw.Pointer(FormatPointer(tokens[i:]...))
+			return res, rawPointerError(err, ptr)
+		}
+		var ok bool
+		switch t := v.(type) {
+		case Map:
+			v, ok = t[token]
+		case map[string]interface{}:
+			v, ok = t[token]
+		case JSONObject:
+			v, ok = t[token]
+		case []interface{}:
+			var idx int
+			if idx, ok = parseIndex(token); ok && idx < len(t) {
+				v = t[idx]
+			} else {
+				ok = false
+			}
+		}
+		if !ok {
+			return nil, &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+		}
+	}
+	return v, nil
+}
+
+// pointerSet sets the value referenced by tokens within v, returning the
+// updated v.
+func pointerSet(v interface{}, ptr string, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token := tokens[0]
+	if raw, ok := asRawJSON(v); ok {
+		res, err := raw.SetPointer(FormatPointer(tokens...), value)
+		return res, rawPointerError(err, ptr)
+	}
+	notFound := &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+	if obj, ok := asObject(v); ok {
+		child, exists := obj[token]
+		if !exists && len(tokens) > 1 {
+			return nil, notFound
+		}
+		nv, err := pointerSet(child, ptr, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		obj[token] = nv
+		return v, nil
+	}
+	switch t := v.(type) {
+	case JSONObject:
+		child, exists := t[token]
+		if !exists && len(tokens) > 1 {
+			return nil, notFound
+		}
+		if len(tokens) == 1 {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			t[token] = data
+			return t, nil
+		}
+		nv, err := child.SetPointer(FormatPointer(tokens[1:]...), value)
+		if err != nil {
+			return nil, rawPointerError(err, ptr)
+		}
+		t[token] = nv
+		return t, nil
+	case []interface{}:
+		idx, ok := parseIndex(token)
+		if len(tokens) == 1 && (token == "-" || (ok && idx == len(t))) {
+			return append(t, value), nil
+		}
+		if !ok || idx >= len(t) {
+			return nil, notFound
+		}
+		nv, err := pointerSet(t[idx], ptr, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		t[idx] = nv
+		return t, nil
+	default:
+		return nil, notFound
+	}
+}
+
+// pointerDelete removes the value referenced by tokens within v, returning
+// the updated v.
+func pointerDelete(v interface{}, ptr string, tokens []string) (interface{}, error) {
+	token := tokens[0]
+	if raw, ok := asRawJSON(v); ok {
+		res, err := raw.DeletePointer(FormatPointer(tokens...))
+		return res, rawPointerError(err, ptr)
+	}
+	notFound := &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+	if obj, ok := asObject(v); ok {
+		child, exists := obj[token]
+		if !exists {
+			return nil, notFound
+		}
+		if len(tokens) == 1 {
+			delete(obj, token)
+			return v, nil
+		}
+		nv, err := pointerDelete(child, ptr, tokens[1:])
+		if err != nil {
+			return nil, err
+		}
+		obj[token] = nv
+		return v, nil
+	}
+	switch t := v.(type) {
+	case JSONObject:
+		child, exists := t[token]
+		if !exists {
+			return nil, notFound
+		}
+		if len(tokens) == 1 {
+			delete(t, token)
+			return t, nil
+		}
+		nv, err := child.DeletePointer(FormatPointer(tokens[1:]...))
+		if err != nil {
+			return nil, rawPointerError(err, ptr)
+		}
+		t[token] = nv
+		return t, nil
+	case []interface{}:
+		idx, ok := parseIndex(token)
+		if !ok || idx >= len(t) {
+			return nil, notFound
+		}
+		if len(tokens) == 1 {
+			return append(t[:idx:idx], t[idx+1:]...), nil
+		}
+		nv, err := pointerDelete(t[idx], ptr, tokens[1:])
+		if err != nil {
+			return nil, err
+		}
+		t[idx] = nv
+		return t, nil
+	default:
+		return nil, notFound
+	}
+}
@@ -0,0 +1,364 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDecodeFailed is returned by Map.Decode when one or more fields could not
+// be decoded
+var ErrDecodeFailed = errors.New("dynamic: decode failed")
+
+// FieldError is a value which Map.Decode or ToMap could not convert
+type FieldError struct {
+	// Path is the dotted path of the value, as described by Map.Get
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("dynamic: field %q: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned by Map.Decode and lists every field which could not
+// be decoded
+type DecodeError struct {
+	Fields []*FieldError
+}
+
+func (e *DecodeError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%s: %v", f.Path, f.Err)
+	}
+	return fmt.Sprintf("%v: %s", ErrDecodeFailed, strings.Join(msgs, "; "))
+}
+
+func (e *DecodeError) Unwrap() error {
+	return ErrDecodeFailed
+}
+
+// MapDecodeOptions configures Map.Decode
+type MapDecodeOptions struct {
+	// TimeLayouts are used to parse strings into Time and time.Time values.
+	// DefaultTimeLayouts are used if empty.
+	TimeLayouts []string
+	// DisallowUnknownFields causes keys which do not match a field of the
+	// destination struct to be reported as errors
+	DisallowUnknownFields bool
+}
+
+type setter interface {
+	Set(value interface{}) error
+}
+
+type layoutSetter interface {
+	Set(value interface{}, layout ...string) error
+}
+
+var (
+	typeGoTime      = reflect.TypeOf(time.Time{})
+	typeUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typeString      = reflect.TypeOf(String{})
+	typeStrings     = reflect.TypeOf(StringOrArrayOfStrings{})
+)
+
+// Decode decodes m into dst, which must be a non-nil pointer, typically to a
+// struct.
+//
+// Struct fields are matched by their json tag or name, preferring an exact
+// match but otherwise matching case-insensitively; fields of embedded structs
+// are promoted as they are by encoding/json.
+//
+// Conversion is weakly typed. Values are assigned to Number, String, Time,
+// Bool, BoolOrString and the other dynamic types through their Set methods
+// and plain strings, bools and numbers are converted by way of String, Bool
+// and Number, so that "3" decodes into an int and true into a string. Single
+// values decode into slices as one element. JSON values are decoded first.
+//
+// Every field which fails is reported by the returned *DecodeError, which
+// wraps ErrDecodeFailed; the remaining fields are still decoded.
+func (m Map) Decode(dst interface{}, opts MapDecodeOptions) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: Decode requires a non-nil pointer, got %T", ErrInvalidType, dst)
+	}
+	d := &mapDecoder{opts: opts}
+	d.decode(m, rv.Elem(), "")
+	if len(d.errs) > 0 {
+		return &DecodeError{Fields: d.errs}
+	}
+	return nil
+}
+
+type mapDecoder struct {
+	opts MapDecodeOptions
+	errs []*FieldError
+}
+
+func (d *mapDecoder) fail(path string, err error) {
+	d.errs = append(d.errs, &FieldError{Path: path, Err: err})
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return pathOptions.escape(key)
+	}
+	return path + "." + pathOptions.escape(key)
+}
+
+func (d *mapDecoder) decode(src interface{}, dst reflect.Value, path string) {
+	if raw, ok := asRawJSON(src); ok {
+		v, err := decode(raw)
+		if err != nil {
+			d.fail(path, err)
+			return
+		}
+		src = v
+	}
+	if dst.Kind() == reflect.Ptr {
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		d.decode(src, dst.Elem(), path)
+		return
+	}
+	if dst.Type() == typeStrings {
+		// decoded as a []string so that arrays and nil are accepted
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+		} else {
+			d.decodeSlice(src, dst, path)
+		}
+		return
+	}
+	if dst.Type() == typeString {
+		src = formatFloat(src)
+	}
+	switch s := dst.Addr().Interface().(type) {
+	case layoutSetter:
+		if err := s.Set(src, d.opts.TimeLayouts...); err != nil {
+			d.fail(path, err)
+		}
+		return
+	case setter:
+		if err := s.Set(src); err != nil {
+			d.fail(path, err)
+		}
+		return
+	}
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return
+	}
+	if dst.Type() == typeGoTime {
+		t := Time{}
+		if err := t.Set(src, d.opts.TimeLayouts...); err != nil {
+			d.fail(path, err)
+			return
+		}
+		tv, _ := t.Time()
+		dst.Set(reflect.ValueOf(tv))
+		return
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return
+	}
+	if dst.Kind() != reflect.Map && dst.Addr().Type().Implements(typeUnmarshaler) {
+		data, err := json.Marshal(src)
+		if err == nil {
+			err = dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data)
+		}
+		if err != nil {
+			d.fail(path, err)
+		}
+		return
+	}
+	if n, ok := src.(json.Number); ok {
+		src = n.String()
+	}
+	var err error
+	switch dst.Kind() {
+	case reflect.Struct:
+		d.decodeStruct(src, dst, path)
+	case reflect.Map:
+		d.decodeMap(src, dst, path)
+	case reflect.Slice, reflect.Array:
+		d.decodeSlice(src, dst, path)
+	case reflect.String:
+		s := String{}
+		if err = s.Set(formatFloat(src), d.opts.TimeLayouts...); err == nil {
+			dst.SetString(s.String())
+		}
+	case reflect.Bool:
+		b := Bool{}
+		if err = b.Set(src); err == nil {
+			v, _ := b.Value().(bool)
+			dst.SetBool(v)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := Number{}
+		if err = n.Set(src); err == nil {
+			i, ok := n.Int64()
+			if !ok || dst.OverflowInt(i) {
+				err = fmt.Errorf("%w: %v overflows %s", ErrInvalidValue, src, dst.Type())
+			} else {
+				dst.SetInt(i)
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := Number{}
+		if err = n.Set(src); err == nil {
+			u, ok := n.Uint64()
+			if !ok || dst.OverflowUint(u) {
+				err = fmt.Errorf("%w: %v overflows %s", ErrInvalidValue, src, dst.Type())
+			} else {
+				dst.SetUint(u)
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		n := Number{}
+		if err = n.Set(src); err == nil {
+			f, ok := n.Float64()
+			if !ok || dst.OverflowFloat(f) {
+				err = fmt.Errorf("%w: %v overflows %s", ErrInvalidValue, src, dst.Type())
+			} else {
+				dst.SetFloat(f)
+			}
+		}
+	default:
+		err = fmt.Errorf("%w: can not decode %T into %s", ErrInvalidType, src, dst.Type())
+	}
+	if err != nil {
+		d.fail(path, err)
+	}
+}
+
+// formatFloat formats src as a string if it is a float; String.Set would
+// otherwise round it to an integer
+func formatFloat(src interface{}) interface{} {
+	switch f := src.(type) {
+	case float64:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(f), 'f', -1, 32)
+	}
+	return src
+}
+
+// objectOf returns v as an object if it is a Map, map[string]interface{} or
+// JSONObject
+func objectOf(v interface{}) (map[string]interface{}, bool) {
+	if obj, ok := v.(JSONObject); ok {
+		res := make(map[string]interface{}, len(obj))
+		for k, e := range obj {
+			res[k] = e
+		}
+		return res, true
+	}
+	return asObject(v)
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (d *mapDecoder) decodeStruct(src interface{}, dst reflect.Value, path string) {
+	obj, ok := objectOf(src)
+	if !ok {
+		d.fail(path, fmt.Errorf("%w: can not decode %T into %s", ErrInvalidType, src, dst.Type()))
+		return
+	}
+	fields := fieldsOf(dst.Type())
+	for _, k := range sortedKeys(obj) {
+		f, ok := matchField(fields, k)
+		if !ok {
+			if d.opts.DisallowUnknownFields {
+				d.fail(joinPath(path, k), fmt.Errorf("%w: unknown field", ErrInvalidValue))
+			}
+			continue
+		}
+		fv, ok := fieldByIndex(dst, f.index, true)
+		if !ok {
+			d.fail(joinPath(path, k), fmt.Errorf("%w: can not set embedded pointer to unexported struct", ErrInvalidType))
+			continue
+		}
+		d.decode(obj[k], fv, joinPath(path, k))
+	}
+}
+
+// matchField returns the field named key, or failing that, the first whose
+// name matches case-insensitively
+func matchField(fields []structField, key string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+func (d *mapDecoder) decodeMap(src interface{}, dst reflect.Value, path string) {
+	obj, ok := objectOf(src)
+	if !ok || dst.Type().Key().Kind() != reflect.String {
+		d.fail(path, fmt.Errorf("%w: can not decode %T into %s", ErrInvalidType, src, dst.Type()))
+		return
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(obj)))
+	}
+	kt, et := dst.Type().Key(), dst.Type().Elem()
+	for _, k := range sortedKeys(obj) {
+		e := reflect.New(et).Elem()
+		d.decode(obj[k], e, joinPath(path, k))
+		dst.SetMapIndex(reflect.ValueOf(k).Convert(kt), e)
+	}
+}
+
+func (d *mapDecoder) decodeSlice(src interface{}, dst reflect.Value, path string) {
+	sv := reflect.ValueOf(src)
+	if k := sv.Kind(); k != reflect.Slice && k != reflect.Array {
+		sv = reflect.ValueOf([]interface{}{src})
+	}
+	n := sv.Len()
+	if dst.Kind() == reflect.Slice {
+		dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+	} else if n > dst.Len() {
+		d.fail(path, fmt.Errorf("%w: %d elements exceed %s", ErrInvalidValue, n, dst.Type()))
+		return
+	}
+	for i := 0; i < dst.Len(); i++ {
+		e := dst.Index(i)
+		if i >= n {
+			e.Set(reflect.Zero(e.Type()))
+			continue
+		}
+		d.decode(sv.Index(i).Interface(), e, path+"["+strconv.Itoa(i)+"]")
+	}
+}
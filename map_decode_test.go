@@ -0,0 +1,123 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeBase struct {
+	ID      string `json:"id"`
+	Created dynamic.Time
+}
+
+type decodeItem struct {
+	Name  string         `json:"name"`
+	Price dynamic.Number `json:"price"`
+}
+
+type decodeTarget struct {
+	decodeBase
+	Title   string                         `json:"title"`
+	Count   int                            `json:"count"`
+	Ratio   float32                        `json:"ratio,omitempty"`
+	Active  bool                           `json:"active"`
+	Flag    dynamic.BoolOrString           `json:"flag"`
+	Tags    dynamic.StringOrArrayOfStrings `json:"tags"`
+	Labels  []string                       `json:"labels"`
+	Items   []decodeItem                   `json:"items"`
+	Meta    map[string]int                 `json:"meta"`
+	When    time.Time                      `json:"when"`
+	Parent  *decodeItem                    `json:"parent"`
+	Extra   interface{}                    `json:"extra"`
+	Raw     json.RawMessage                `json:"raw"`
+	Ignored string                         `json:"-"`
+}
+
+func TestMapDecode(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{
+		"id":      7,
+		"created": "2021-03-04T05:06:07Z",
+		"TITLE":   34.5,
+		"count":   "42",
+		"ratio":   json.Number("0.25"),
+		"active":  "true",
+		"flag":    "maybe",
+		"tags":    []interface{}{"a", "b"},
+		"labels":  "single",
+		"items":   dynamic.JSON(`[{"name": "x", "price": "1.5"}, {"name": "y", "price": 2}]`),
+		"meta":    map[string]interface{}{"a": 1.0, "b": "2"},
+		"when":    "2022-01-02T03:04:05Z",
+		"parent":  dynamic.Map{"name": "p"},
+		"extra":   []interface{}{1.0},
+		"raw":     dynamic.JSON(`{"k": true}`),
+		"Ignored": "nope",
+		"unknown": 1,
+	}
+	var dst decodeTarget
+	assert.NoError(m.Decode(&dst, dynamic.MapDecodeOptions{}))
+
+	assert.Equal("7", dst.ID)
+	created, ok := dst.Created.Time()
+	assert.True(ok)
+	assert.Equal(2021, created.Year())
+	assert.Equal("34.5", dst.Title)
+	assert.Equal(42, dst.Count)
+	assert.Equal(float32(0.25), dst.Ratio)
+	assert.True(dst.Active)
+	assert.Equal("maybe", dst.Flag.String())
+	assert.Equal(dynamic.StringOrArrayOfStrings{"a", "b"}, dst.Tags)
+	assert.Equal([]string{"single"}, dst.Labels)
+	assert.Len(dst.Items, 2)
+	assert.Equal("y", dst.Items[1].Name)
+	f, _ := dst.Items[0].Price.Float64()
+	assert.Equal(1.5, f)
+	assert.Equal(map[string]int{"a": 1, "b": 2}, dst.Meta)
+	assert.Equal(2022, dst.When.Year())
+	assert.NotNil(dst.Parent)
+	assert.Equal("p", dst.Parent.Name)
+	assert.Equal([]interface{}{1.0}, dst.Extra)
+	assert.JSONEq(`{"k": true}`, string(dst.Raw))
+	assert.Empty(dst.Ignored)
+
+	var strs struct {
+		Label dynamic.String                 `json:"label"`
+		Tags  dynamic.StringOrArrayOfStrings `json:"tags"`
+		Other dynamic.StringOrArrayOfStrings `json:"other"`
+	}
+	strs.Tags = dynamic.StringOrArrayOfStrings{"old"}
+	assert.NoError(dynamic.Map{"label": 2.75, "tags": nil, "other": "x"}.Decode(&strs, dynamic.MapDecodeOptions{}))
+	assert.Equal("2.75", strs.Label.String())
+	assert.Nil(strs.Tags)
+	assert.Equal(dynamic.StringOrArrayOfStrings{"x"}, strs.Other)
+}
+
+func TestMapDecodeErrors(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{
+		"count":   "many",
+		"ratio":   1e300,
+		"active":  []interface{}{},
+		"items":   []interface{}{dynamic.Map{"name": "ok"}, dynamic.Map{"price": "free"}},
+		"meta":    map[string]interface{}{"a.b": "x"},
+		"unknown": 1,
+	}
+	var dst decodeTarget
+	err := m.Decode(&dst, dynamic.MapDecodeOptions{DisallowUnknownFields: true})
+	assert.ErrorIs(err, dynamic.ErrDecodeFailed)
+	var derr *dynamic.DecodeError
+	assert.True(errors.As(err, &derr))
+	paths := make([]string, len(derr.Fields))
+	for i, f := range derr.Fields {
+		paths[i] = f.Path
+	}
+	assert.Equal([]string{"active", "count", "items[1].price", `meta.a\.b`, "ratio", "unknown"}, paths)
+	assert.Equal("ok", dst.Items[0].Name)
+
+	assert.ErrorIs(m.Decode(dst, dynamic.MapDecodeOptions{}), dynamic.ErrInvalidType)
+}
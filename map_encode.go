@@ -0,0 +1,237 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type valuer interface {
+	Value() interface{}
+}
+
+var typeMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// ToMap converts v, a struct, a map with string keys or a pointer to either,
+// into a Map.
+//
+// Struct fields are keyed by their json tag or name. Fields tagged "-" are
+// skipped, as are empty fields tagged omitempty, and fields of embedded
+// structs are promoted as they are by encoding/json. Nested structs and maps
+// become Map and slices and arrays []interface{}.
+//
+// Number, String, Time, Bool and the other dynamic types are converted to
+// the result of their Value method; Map values are converted recursively and
+// JSON values are retained. Values of any other type which implements
+// json.Marshaler are encoded as JSON. If v itself implements json.Marshaler,
+// it is encoded and decoded into the Map.
+//
+// A *FieldError is returned if a value, such as a func or chan, can not be
+// converted or if v contains a cycle.
+func ToMap(v interface{}) (Map, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map {
+		return nil, fmt.Errorf("%w: ToMap requires a struct or map, got %T", ErrInvalidType, v)
+	}
+	e := &mapEncoder{seen: map[visit]struct{}{}}
+	res, err := e.toValue(reflect.ValueOf(v), "")
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := res.(JSON); ok {
+		if res, err = decode(raw); err != nil {
+			return nil, err
+		}
+		obj, ok := asObject(res)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T is not encoded as an object", ErrInvalidType, v)
+		}
+		return Map(obj), nil
+	}
+	m, _ := res.(Map)
+	return m, nil
+}
+
+// visit identifies a pointer, map or slice which is being converted
+type visit struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+type mapEncoder struct {
+	// seen holds the values currently being converted so that cycles are
+	// detected
+	seen map[visit]struct{}
+}
+
+func (e *mapEncoder) visit(k visit, path string) error {
+	if _, ok := e.seen[k]; ok {
+		return &FieldError{Path: path, Err: fmt.Errorf("%w: cycle through %s", ErrInvalidValue, k.typ)}
+	}
+	e.seen[k] = struct{}{}
+	return nil
+}
+
+func (e *mapEncoder) toValue(rv reflect.Value, path string) (interface{}, error) {
+	var visited []visit
+	defer func() {
+		for _, k := range visited {
+			delete(e.seen, k)
+		}
+	}()
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Kind() == reflect.Ptr {
+			k := visit{ptr: rv.Pointer(), typ: rv.Type()}
+			if err := e.visit(k, path); err != nil {
+				return nil, err
+			}
+			visited = append(visited, k)
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if !rv.CanAddr() {
+		// dynamic types such as Number and String implement Value with a
+		// pointer receiver
+		addr := reflect.New(rv.Type())
+		addr.Elem().Set(rv)
+		rv = addr.Elem()
+	}
+	v := rv.Interface()
+	if raw, ok := asRawJSON(v); ok {
+		return raw, nil
+	}
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	if val, ok := rv.Addr().Interface().(valuer); ok {
+		return val.Value(), nil
+	}
+	// StringOrArrayOfStrings is converted as a slice; its MarshalJSON
+	// does not produce valid json
+	if rv.Type() != typeStrings && rv.Addr().Type().Implements(typeMarshaler) {
+		return marshalValue(rv.Addr().Interface(), path)
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		k := visit{ptr: rv.Pointer(), typ: rv.Type()}
+		if rv.Kind() == reflect.Slice {
+			k.len = rv.Len()
+		}
+		if err := e.visit(k, path); err != nil {
+			return nil, err
+		}
+		visited = append(visited, k)
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return e.structToMap(rv, path)
+	case reflect.Map:
+		return e.mapToMap(rv, path)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v, nil
+		}
+		fallthrough
+	case reflect.Array:
+		res := make([]interface{}, rv.Len())
+		for i := range res {
+			el, err := e.toValue(rv.Index(i), path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+			res[i] = el
+		}
+		return res, nil
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return nil, &FieldError{Path: path, Err: fmt.Errorf("%w: can not convert %s", ErrInvalidType, rv.Type())}
+	default:
+		return v, nil
+	}
+}
+
+func marshalValue(v interface{}, path string) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, &FieldError{Path: path, Err: err}
+	}
+	return JSON(data), nil
+}
+
+func (e *mapEncoder) structToMap(rv reflect.Value, path string) (interface{}, error) {
+	fields := fieldsOf(rv.Type())
+	res := make(Map, len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		v, err := e.toValue(fv, joinPath(path, f.name))
+		if err != nil {
+			return nil, err
+		}
+		if f.omitEmpty && v == nil {
+			continue
+		}
+		res[f.name] = v
+	}
+	return res, nil
+}
+
+func (e *mapEncoder) mapToMap(rv reflect.Value, path string) (interface{}, error) {
+	res := make(Map, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		var key string
+		switch k := iter.Key(); k.Kind() {
+		case reflect.String:
+			key = k.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return nil, &FieldError{Path: path, Err: fmt.Errorf("%w: can not convert %s keys", ErrInvalidType, k.Type())}
+		}
+		v, err := e.toValue(iter.Value(), joinPath(path, key))
+		if err != nil {
+			return nil, err
+		}
+		res[key] = v
+	}
+	return res, nil
+}
+
+// isEmptyValue reports whether v is empty per the omitempty rules of
+// encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+type encodeBase struct {
+	ID string `json:"id"`
+}
+
+type encodeChild struct {
+	Name string `json:"name,omitempty"`
+}
+
+type encodeSource struct {
+	encodeBase
+	*encodeChild
+	Count    dynamic.Number         `json:"count"`
+	Title    dynamic.String         `json:"title"`
+	Flag     dynamic.BoolOrString   `json:"flag"`
+	When     time.Time              `json:"when"`
+	Empty    string                 `json:"empty,omitempty"`
+	Missing  dynamic.Number         `json:"missing,omitempty"`
+	Hidden   string                 `json:"-"`
+	Children []encodeChild          `json:"children"`
+	Lookup   map[int]bool           `json:"lookup"`
+	Raw      dynamic.JSON           `json:"raw"`
+	Nested   *encodeChild           `json:"nested"`
+	Any      interface{}            `json:"any"`
+	Ordered  *dynamic.OrderedObject `json:"ordered,omitempty"`
+	private  string
+}
+
+type encodeLevel int
+
+func (l encodeLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("level-%d", l))
+}
+
+type encodeNode struct {
+	Name string      `json:"name"`
+	Next *encodeNode `json:"next,omitempty"`
+}
+
+func TestToMap(t *testing.T) {
+	assert := require.New(t)
+	when := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	count, err := dynamic.NewNumber(3)
+	assert.NoError(err)
+	title, err := dynamic.NewString("title")
+	assert.NoError(err)
+	flag, err := dynamic.NewBoolOrString(true)
+	assert.NoError(err)
+	src := encodeSource{
+		encodeBase: encodeBase{ID: "x"},
+		Count:      count,
+		Title:      title,
+		Flag:       flag,
+		When:       when,
+		Hidden:     "hidden",
+		Children:   []encodeChild{{Name: "a"}, {}},
+		Lookup:     map[int]bool{1: true},
+		Raw:        dynamic.JSON(`{"k": 1}`),
+		Any:        &encodeChild{Name: "any"},
+		private:    "private",
+	}
+	m, err := dynamic.ToMap(&src)
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{
+		"id":       "x",
+		"count":    int64(3),
+		"title":    "title",
+		"flag":     true,
+		"when":     when,
+		"children": []interface{}{dynamic.Map{"name": "a"}, dynamic.Map{}},
+		"lookup":   dynamic.Map{"1": true},
+		"raw":      dynamic.JSON(`{"k": 1}`),
+		"nested":   nil,
+		"any":      dynamic.Map{"name": "any"},
+	}, m)
+
+	data, err := json.Marshal(m)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"id": "x",
+		"count": 3,
+		"title": "title",
+		"flag": true,
+		"when": "2021-03-04T05:06:07Z",
+		"children": [{"name": "a"}, {}],
+		"lookup": {"1": true},
+		"raw": {"k": 1},
+		"nested": null,
+		"any": {"name": "any"}
+	}`, string(data))
+
+	_, err = dynamic.ToMap(map[string]interface{}{"f": func() {}})
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+	var ferr *dynamic.FieldError
+	assert.ErrorAs(err, &ferr)
+	assert.Equal("f", ferr.Path)
+
+	_, err = dynamic.ToMap([]string{})
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+
+	m, err = dynamic.ToMap(map[string]interface{}{"level": encodeLevel(2), "levels": []encodeLevel{1}})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"level": dynamic.JSON(`"level-2"`), "levels": []interface{}{dynamic.JSON(`"level-1"`)}}, m)
+
+	om := &dynamic.OrderedMap{}
+	om.Set("a", 1)
+	m, err = dynamic.ToMap(om)
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"a": json.Number("1")}, m)
+
+	shared := &encodeNode{Name: "shared"}
+	m, err = dynamic.ToMap(map[string]interface{}{"a": shared, "b": shared})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"name": "shared"}, m["b"])
+
+	node := &encodeNode{Name: "a"}
+	node.Next = &encodeNode{Name: "b", Next: node}
+	_, err = dynamic.ToMap(node)
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+	assert.ErrorAs(err, &ferr)
+	assert.Equal("next.next", ferr.Path)
+
+	type tagged struct {
+		Tags  dynamic.StringOrArrayOfStrings `json:"tags"`
+		Empty dynamic.StringOrArrayOfStrings `json:"empty"`
+	}
+	m, err = dynamic.ToMap(tagged{Tags: dynamic.StringOrArrayOfStrings{"a", "b"}})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"tags": []interface{}{"a", "b"}, "empty": nil}, m)
+
+	list := []interface{}{nil}
+	list[0] = list
+	_, err = dynamic.ToMap(map[string]interface{}{"list": list})
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+}
@@ -0,0 +1,134 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// pathOptions are the FlattenOptions which describe dotted paths
+var pathOptions = FlattenOptions{Brackets: true}
+
+// parsePath parses the dotted path into reference tokens
+func parsePath(path string) ([]flatToken, []string, error) {
+	tokens, err := pathOptions.parse(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, tokenRefs(tokens), nil
+}
+
+// keyNotFound maps ErrPointerNotFound to ErrKeyNotFound
+func keyNotFound(path string, err error) error {
+	if errors.Is(err, ErrPointerNotFound) {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, path)
+	}
+	return err
+}
+
+// Get returns the value at the dotted path, such as
+// "settings.index.number_of_shards". Array elements are referenced with
+// brackets, as in "fields[0].name". A backslash escapes a literal ".", "[",
+// "]" or "\" within a key.
+//
+// Nested Map, map[string]interface{}, JSONObject, []interface{} and JSON values
+// are traversed. An error wrapping ErrKeyNotFound is returned if path does not
+// exist.
+func (m Map) Get(path string) (interface{}, error) {
+	_, refs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := pointerGet(m, path, refs)
+	return v, keyNotFound(path, err)
+}
+
+// Set sets the value at the dotted path, as described by Get. Missing
+// intermediate objects and arrays are created. An array is only extended by
+// referencing the index one past its last element.
+//
+// An error wrapping ErrInvalidType is returned if an intermediate value is
+// neither an object nor an array, or is not of the kind path references. An
+// error wrapping ErrInvalidValue is returned if an index is beyond the end of
+// its array.
+func (m Map) Set(path string, value interface{}) error {
+	tokens, _, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	_, err = setPath(m, path, tokens, value)
+	return err
+}
+
+// setPath sets value at tokens within v, creating intermediate values, and
+// returns the updated v
+func setPath(v interface{}, path string, tokens []flatToken, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	if _, ok := v.(JSONObject); ok {
+		return pointerSet(v, path, tokenRefs(tokens), value)
+	}
+	if _, ok := asRawJSON(v); ok {
+		return pointerSet(v, path, tokenRefs(tokens), value)
+	}
+	t := tokens[0]
+	if t.isIndex {
+		if v == nil {
+			v = []interface{}{}
+		}
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %q references an element of a %T", ErrInvalidType, path, v)
+		}
+		if t.index > len(arr) {
+			return nil, fmt.Errorf("%w: %q index %d is out of range", ErrInvalidValue, path, t.index)
+		}
+		if t.index == len(arr) {
+			arr = append(arr, nil)
+		}
+		e, err := setPath(arr[t.index], path, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		arr[t.index] = e
+		return arr, nil
+	}
+	if v == nil {
+		v = Map{}
+	}
+	obj, ok := asObject(v)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q references a member of a %T", ErrInvalidType, path, v)
+	}
+	e, err := setPath(obj[t.key], path, tokens[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	obj[t.key] = e
+	return v, nil
+}
+
+// tokenRefs returns the JSON Pointer reference tokens of tokens
+func tokenRefs(tokens []flatToken) []string {
+	refs := make([]string, len(tokens))
+	for i, t := range tokens {
+		refs[i] = t.key
+		if t.isIndex {
+			refs[i] = strconv.Itoa(t.index)
+		}
+	}
+	return refs
+}
+
+// Delete removes the value at the dotted path, as described by Get. Elements
+// removed from arrays shift those which follow. An error wrapping
+// ErrKeyNotFound is returned if path does not exist.
+func (m Map) Delete(path string) error {
+	_, refs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	_, err = pointerDelete(m, path, refs)
+	return keyNotFound(path, err)
+}
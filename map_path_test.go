@@ -0,0 +1,77 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapPath(t *testing.T) {
+	assert := require.New(t)
+	var m dynamic.Map
+	assert.NoError(json.Unmarshal([]byte(`{
+		"settings": {"index": {"number_of_shards": 3}},
+		"fields": [{"name": "a"}, {"name": "b"}],
+		"a.b": "dotted"
+	}`), &m))
+	m["raw"] = dynamic.JSON(`{"list": [1, 2]}`)
+
+	v, err := m.Get("settings.index.number_of_shards")
+	assert.NoError(err)
+	assert.Equal(float64(3), v)
+
+	v, err = m.Get("fields[1].name")
+	assert.NoError(err)
+	assert.Equal("b", v)
+
+	v, err = m.Get(`a\.b`)
+	assert.NoError(err)
+	assert.Equal("dotted", v)
+
+	v, err = m.Get("raw.list[1]")
+	assert.NoError(err)
+	assert.Equal(dynamic.JSON("2"), v)
+
+	_, err = m.Get("settings.missing")
+	assert.ErrorIs(err, dynamic.ErrKeyNotFound)
+	_, err = m.Get("fields[5]")
+	assert.ErrorIs(err, dynamic.ErrKeyNotFound)
+	_, err = m.Get("fields[x]")
+	assert.ErrorIs(err, dynamic.ErrInvalidValue)
+
+	assert.NoError(m.Set("settings.index.refresh", "1s"))
+	assert.NoError(m.Set("new.list[0]", nil))
+	assert.NoError(m.Set("new.list[1]", nil))
+	assert.NoError(m.Set("new.list[2].id", 7))
+	assert.ErrorIs(m.Set("new.list[4]", 1), dynamic.ErrInvalidValue)
+	assert.ErrorIs(m.Set("other[1000000000]", 1), dynamic.ErrInvalidValue)
+	_, err = m.Get("other")
+	assert.ErrorIs(err, dynamic.ErrKeyNotFound)
+	assert.NoError(m.Set(`x\.y`, true))
+	assert.NoError(m.Set("fields[0].name", "z"))
+	assert.NoError(m.Set("raw.list[0]", 9))
+	assert.ErrorIs(m.Set("a\\.b.c", 1), dynamic.ErrInvalidType)
+	assert.ErrorIs(m.Set("settings[0]", 1), dynamic.ErrInvalidType)
+
+	n, err := m.Number("/new/list/2/id")
+	assert.NoError(err)
+	i, _ := n.Int()
+	assert.Equal(7, i)
+
+	assert.NoError(m.Delete("fields[0]"))
+	assert.NoError(m.Delete("settings.index.number_of_shards"))
+	assert.ErrorIs(m.Delete("settings.index.number_of_shards"), dynamic.ErrKeyNotFound)
+
+	data, err := json.Marshal(m)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"settings": {"index": {"refresh": "1s"}},
+		"fields": [{"name": "b"}],
+		"a.b": "dotted",
+		"x.y": true,
+		"new": {"list": [null, null, {"id": 7}]},
+		"raw": {"list": [9, 2]}
+	}`, string(data))
+}
@@ -0,0 +1,182 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeyNotFound is returned when a key or path does not exist within a Map
+var ErrKeyNotFound = errors.New("dynamic: key not found")
+
+// lookup returns the value at path. path is a JSON Pointer if it begins with
+// "/", otherwise it is a key of m.
+func (m Map) lookup(path string) (interface{}, error) {
+	if strings.HasPrefix(path, "/") {
+		v, err := m.Pointer(path)
+		if errors.Is(err, ErrPointerNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, path)
+		}
+		return v, err
+	}
+	v, ok := m[path]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, path)
+	}
+	return v, nil
+}
+
+func valueError(path string, err error) error {
+	return fmt.Errorf("dynamic: value at %q: %w", path, err)
+}
+
+// String returns the value at path as a String. path is a JSON Pointer if it
+// begins with "/", otherwise it is a key of m.
+//
+// The value is coerced per NewString; JSON values must be json strings.
+func (m Map) String(path string) (String, error) {
+	v, err := m.lookup(path)
+	if err != nil {
+		return String{}, err
+	}
+	if raw, ok := asRawJSON(v); ok {
+		if raw.IsNull() {
+			return String{}, nil
+		}
+		if v, err = raw.String(); err != nil {
+			return String{}, valueError(path, err)
+		}
+	}
+	s, err := NewString(v)
+	if err != nil {
+		return String{}, valueError(path, err)
+	}
+	return s, nil
+}
+
+// Number returns the value at path as a Number. path is a JSON Pointer if it
+// begins with "/", otherwise it is a key of m.
+//
+// The value is coerced per NewNumber; JSON values must be json numbers.
+func (m Map) Number(path string) (Number, error) {
+	v, err := m.lookup(path)
+	if err != nil {
+		return Number{}, err
+	}
+	if raw, ok := asRawJSON(v); ok {
+		if raw.IsNull() {
+			return Number{}, nil
+		}
+		n, err := raw.Number()
+		if err != nil {
+			return Number{}, valueError(path, err)
+		}
+		return n, nil
+	}
+	n, err := NewNumber(v)
+	if err != nil {
+		return Number{}, valueError(path, err)
+	}
+	return n, nil
+}
+
+// Bool returns the value at path as a Bool. path is a JSON Pointer if it
+// begins with "/", otherwise it is a key of m.
+//
+// The value is coerced per NewBool; JSON values must be json booleans.
+func (m Map) Bool(path string) (Bool, error) {
+	v, err := m.lookup(path)
+	if err != nil {
+		return Bool{}, err
+	}
+	if raw, ok := asRawJSON(v); ok {
+		if raw.IsNull() {
+			return Bool{}, nil
+		}
+		if v, err = raw.Bool(); err != nil {
+			return Bool{}, valueError(path, err)
+		}
+	}
+	b, err := NewBool(v)
+	if err != nil {
+		return Bool{}, valueError(path, err)
+	}
+	return b, nil
+}
+
+// Time returns the value at path as a Time, parsing strings with the first of
+// layouts which succeeds. DefaultTimeLayouts are used if layouts is empty.
+// path is a JSON Pointer if it begins with "/", otherwise it is a key of m.
+func (m Map) Time(path string, layouts ...string) (Time, error) {
+	v, err := m.lookup(path)
+	if err != nil {
+		return Time{}, err
+	}
+	if raw, ok := asRawJSON(v); ok {
+		if raw.IsNull() {
+			return Time{}, nil
+		}
+		if v, err = raw.String(); err != nil {
+			return Time{}, valueError(path, err)
+		}
+	}
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	t := Time{}
+	if err := t.Set(v, layouts...); err != nil {
+		return Time{}, valueError(path, err)
+	}
+	return t, nil
+}
+
+// Strings returns the value at path as a StringOrArrayOfStrings. Strings
+// result in a single element while arrays must contain only strings. path is
+// a JSON Pointer if it begins with "/", otherwise it is a key of m.
+func (m Map) Strings(path string) (StringOrArrayOfStrings, error) {
+	v, err := m.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return StringOrArrayOfStrings{t}, nil
+	case []string:
+		return StringOrArrayOfStrings(t), nil
+	case StringOrArrayOfStrings:
+		return t, nil
+	case []interface{}:
+		res := make(StringOrArrayOfStrings, len(t))
+		for i, e := range t {
+			s, ok := e.(string)
+			if !ok {
+				return nil, valueError(path, &ElementError{Index: i, Err: fmt.Errorf("%w: %T is not a string", ErrInvalidType, e)})
+			}
+			res[i] = s
+		}
+		return res, nil
+	}
+	if raw, ok := asRawJSON(v); ok {
+		var res StringOrArrayOfStrings
+		switch raw.Kind() {
+		case KindNull:
+		case KindArray:
+			var a JSONArray
+			if err = a.UnmarshalJSON(raw); err == nil {
+				res, err = a.Strings()
+			}
+		default:
+			var s string
+			if s, err = raw.String(); err == nil {
+				res = StringOrArrayOfStrings{s}
+			}
+		}
+		if err != nil {
+			return nil, valueError(path, err)
+		}
+		return res, nil
+	}
+	return nil, valueError(path, fmt.Errorf("%w <%T>", ErrInvalidType, v))
+}
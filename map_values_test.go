@@ -0,0 +1,97 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapTypedGetters(t *testing.T) {
+	assert := require.New(t)
+	var m dynamic.Map
+	assert.NoError(json.Unmarshal([]byte(`{
+		"name": "ann",
+		"age": 34,
+		"admin": "true",
+		"created": "2021-03-04T05:06:07Z",
+		"day": "2021-03-04",
+		"tags": ["a", "b"],
+		"tag": "c",
+		"mixed": ["a", 1],
+		"nested": {"count": 2.5, "active": false},
+		"empty": null
+	}`), &m))
+	m["raw"] = dynamic.JSON(`{"list": ["x", "y"], "n": 7, "s": "str\n"}`)
+
+	s, err := m.String("name")
+	assert.NoError(err)
+	assert.Equal("ann", s.String())
+	s, err = m.String("age")
+	assert.NoError(err)
+	assert.Equal("34", s.String())
+	s, err = m.String("/raw/s")
+	assert.NoError(err)
+	assert.Equal("str\n", s.String())
+
+	n, err := m.Number("/nested/count")
+	assert.NoError(err)
+	f, _ := n.Float64()
+	assert.Equal(2.5, f)
+	n, err = m.Number("/raw/n")
+	assert.NoError(err)
+	i, _ := n.Int64()
+	assert.Equal(int64(7), i)
+	n, err = m.Number("empty")
+	assert.NoError(err)
+	assert.True(n.IsNil())
+
+	b, err := m.Bool("admin")
+	assert.NoError(err)
+	assert.Equal(true, b.Value())
+	b, err = m.Bool("/nested/active")
+	assert.NoError(err)
+	assert.Equal(false, b.Value())
+
+	tm, err := m.Time("created")
+	assert.NoError(err)
+	v, _ := tm.Time()
+	assert.Equal(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), v)
+	tm, err = m.Time("day", "2006-01-02")
+	assert.NoError(err)
+	v, _ = tm.Time()
+	assert.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), v)
+
+	strs, err := m.Strings("tags")
+	assert.NoError(err)
+	assert.Equal(dynamic.StringOrArrayOfStrings{"a", "b"}, strs)
+	strs, err = m.Strings("tag")
+	assert.NoError(err)
+	assert.Equal(dynamic.StringOrArrayOfStrings{"c"}, strs)
+	strs, err = m.Strings("/raw/list")
+	assert.NoError(err)
+	assert.Equal(dynamic.StringOrArrayOfStrings{"x", "y"}, strs)
+
+	_, err = m.Strings("mixed")
+	var eerr *dynamic.ElementError
+	assert.ErrorAs(err, &eerr)
+	assert.Equal(1, eerr.Index)
+	_, err = m.Number("name")
+	assert.Error(err)
+	_, err = m.Bool("/raw/n")
+	assert.ErrorIs(err, dynamic.ErrInvalidType)
+	_, err = m.String("missing")
+	assert.ErrorIs(err, dynamic.ErrKeyNotFound)
+	_, err = m.String("/nested/missing")
+	assert.ErrorIs(err, dynamic.ErrKeyNotFound)
+
+	// keys are literal; only JSON Pointers are traversed
+	m["a.b"] = "dotted"
+	s, err = m.String("a.b")
+	assert.NoError(err)
+	assert.Equal("dotted", s.String())
+	_, err = m.Number("nested.count")
+	assert.ErrorIs(err, dynamic.ErrKeyNotFound)
+}
@@ -0,0 +1,234 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrMergeConflict is returned by Map.Merge when values conflict and the
+// ConflictStrategy is ConflictError
+var ErrMergeConflict = errors.New("dynamic: merge conflict")
+
+// ArrayStrategy determines how arrays are merged
+type ArrayStrategy uint8
+
+const (
+	// ArrayReplace replaces the left array with the right
+	ArrayReplace ArrayStrategy = iota
+	// ArrayAppend appends the elements of the right array to the left
+	ArrayAppend
+	// ArrayUnion appends the elements of the right array which are not in the
+	// left. If MergeStrategy.UnionKey is set, objects with equal values for
+	// the key are considered the same element and are merged.
+	ArrayUnion
+)
+
+// ConflictStrategy determines how values which differ, and which can not be
+// merged, are resolved
+type ConflictStrategy uint8
+
+const (
+	// ConflictRight keeps the value of the right Map
+	ConflictRight ConflictStrategy = iota
+	// ConflictLeft keeps the value of the left Map
+	ConflictLeft
+	// ConflictError fails the merge
+	ConflictError
+)
+
+// MergeStrategy determines how values are merged
+type MergeStrategy struct {
+	Arrays ArrayStrategy
+	// UnionKey is the key by which objects within arrays are matched when
+	// Arrays is ArrayUnion
+	UnionKey  string
+	Conflicts ConflictStrategy
+}
+
+// MergeOptions configures Map.Merge
+type MergeOptions struct {
+	// Default is the strategy used for paths not in Paths
+	Default MergeStrategy
+	// Paths overrides the strategy for the value at each JSON Pointer and
+	// everything beneath it, unless a longer path is also present.
+	Paths map[string]MergeStrategy
+}
+
+// strategy returns the strategy for the value at path
+func (opts MergeOptions) strategy(path []string) MergeStrategy {
+	for i := len(path); i >= 0 && len(opts.Paths) > 0; i-- {
+		if s, ok := opts.Paths[FormatPointer(path[:i]...)]; ok {
+			return s
+		}
+	}
+	return opts.Default
+}
+
+// MergeConflict is a value present in both Maps of a merge which differs and
+// could not be merged
+type MergeConflict struct {
+	// Path is the JSON Pointer of the value
+	Path  string
+	Left  interface{}
+	Right interface{}
+	// Resolution is the strategy used to resolve the conflict
+	Resolution ConflictStrategy
+}
+
+// Merge deeply merges other into m. Objects are merged member by member and
+// arrays per the ArrayStrategy for their path. Any other values which differ
+// are conflicts, resolved per the ConflictStrategy for their path; every
+// conflict is returned for auditing purposes.
+//
+// Nested Map and map[string]interface{} values are treated as objects and
+// JSON values are decoded. If an error is returned, m is left unmodified.
+// Values of other are not copied.
+func (m Map) Merge(other Map, opts MergeOptions) ([]MergeConflict, error) {
+	mg := &merger{opts: opts}
+	res, err := mg.merge(m, other, nil)
+	if err != nil {
+		return mg.conflicts, err
+	}
+	obj, _ := asObject(res)
+	for k := range m {
+		delete(m, k)
+	}
+	for k, v := range obj {
+		m[k] = v
+	}
+	return mg.conflicts, nil
+}
+
+type merger struct {
+	opts      MergeOptions
+	conflicts []MergeConflict
+}
+
+func (mg *merger) merge(left, right interface{}, path []string) (interface{}, error) {
+	if raw, ok := asRawJSON(left); ok {
+		var err error
+		if left, err = decode(raw); err != nil {
+			return nil, err
+		}
+	}
+	if raw, ok := asRawJSON(right); ok {
+		var err error
+		if right, err = decode(raw); err != nil {
+			return nil, err
+		}
+	}
+	lobj, lok := asObject(left)
+	robj, rok := asObject(right)
+	if lok && rok {
+		res := make(map[string]interface{}, len(lobj)+len(robj))
+		for k, v := range lobj {
+			res[k] = v
+		}
+		for k, rv := range robj {
+			lv, exists := res[k]
+			if !exists {
+				res[k] = rv
+				continue
+			}
+			v, err := mg.merge(lv, rv, childPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			res[k] = v
+		}
+		if _, ok := left.(Map); ok {
+			return Map(res), nil
+		}
+		return res, nil
+	}
+	larr, lok := left.([]interface{})
+	rarr, rok := right.([]interface{})
+	if lok && rok {
+		return mg.mergeArrays(larr, rarr, path)
+	}
+	if equalAny(left, right) {
+		return left, nil
+	}
+	s := mg.opts.strategy(path)
+	mg.conflicts = append(mg.conflicts, MergeConflict{
+		Path:       FormatPointer(path...),
+		Left:       left,
+		Right:      right,
+		Resolution: s.Conflicts,
+	})
+	switch s.Conflicts {
+	case ConflictLeft:
+		return left, nil
+	case ConflictError:
+		return nil, fmt.Errorf("%w at %q", ErrMergeConflict, FormatPointer(path...))
+	default:
+		return right, nil
+	}
+}
+
+func (mg *merger) mergeArrays(left, right []interface{}, path []string) (interface{}, error) {
+	s := mg.opts.strategy(path)
+	switch s.Arrays {
+	case ArrayAppend:
+		res := make([]interface{}, 0, len(left)+len(right))
+		res = append(res, left...)
+		return append(res, right...), nil
+	case ArrayUnion:
+		res := append([]interface{}{}, left...)
+	elements:
+		for _, rv := range right {
+			if key, ok := unionKey(rv, s.UnionKey); ok {
+				for i, lv := range res {
+					if lk, ok := unionKey(lv, s.UnionKey); ok && equalAny(lk, key) {
+						v, err := mg.merge(lv, rv, childPath(path, strconv.Itoa(i)))
+						if err != nil {
+							return nil, err
+						}
+						res[i] = v
+						continue elements
+					}
+				}
+			} else {
+				for _, lv := range res {
+					if equalAny(lv, rv) {
+						continue elements
+					}
+				}
+			}
+			res = append(res, rv)
+		}
+		return res, nil
+	default:
+		return right, nil
+	}
+}
+
+// unionKey returns the value of key if v is an object containing it
+func unionKey(v interface{}, key string) (interface{}, bool) {
+	if key == "" {
+		return nil, false
+	}
+	obj, ok := asObject(v)
+	if !ok {
+		return nil, false
+	}
+	k, ok := obj[key]
+	return k, ok
+}
+
+// equalAny reports whether the json encodings of a and b are semantically
+// equal
+func equalAny(a, b interface{}) bool {
+	ad, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bd, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	eq, err := equalJSON(ad, bd)
+	return err == nil && eq
+}
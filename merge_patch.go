@@ -0,0 +1,349 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch applies the JSON Merge Patch (RFC 7386) patch to d, returning
+// the result. Members of patch which are null are removed from d. The
+// formatting and member order of d are otherwise preserved.
+func (d JSON) MergePatch(patch JSON) (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	return mergePatchJSON(d, patch)
+}
+
+// mergePatchJSON applies patch to target in a single pass over each. Members
+// of target which are not patched are copied along with the formatting which
+// precedes them; patched values are merged recursively and new members are
+// appended.
+func mergePatchJSON(target JSON, patch JSON) (JSON, error) {
+	patch = patch.trimSpace()
+	if !patch.IsObject() {
+		return compact(patch)
+	}
+	pc, err := scanContainer(patch, 0)
+	if err != nil {
+		return nil, err
+	}
+	// the last of any duplicate keys takes precedence, as it would were the
+	// members applied in order
+	pv := make(map[string]JSON, len(pc.members))
+	for _, m := range pc.members {
+		pv[m.key] = patch[m.value.start:m.value.end]
+	}
+	target = target.trimSpace()
+	tc := container{span: span{start: 0, end: 2}, kind: KindObject}
+	if target.IsObject() {
+		if tc, err = scanContainer(target, 0); err != nil {
+			return nil, err
+		}
+	} else {
+		target = JSON("{}")
+	}
+	last := make(map[string]int, len(tc.members))
+	for i, m := range tc.members {
+		last[m.key] = i
+	}
+	interior := tc.interior()
+	res := make(JSON, 0, len(target)+len(patch))
+	res = append(res, '{')
+	n := 0
+	for i, m := range tc.members {
+		v, patched := pv[m.key]
+		if patched && (v.IsNull() || i != last[m.key]) {
+			continue
+		}
+		if n == 0 {
+			res = append(res, target[interior.start:tc.members[0].span.start]...)
+		} else {
+			res = append(res, target[tc.members[i-1].span.end:m.span.start]...)
+		}
+		n++
+		if !patched {
+			res = append(res, target[m.span.start:m.span.end]...)
+			continue
+		}
+		nv, err := mergePatchJSON(target[m.value.start:m.value.end], v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, target[m.span.start:m.value.start]...)
+		res = append(res, nv...)
+	}
+	for _, m := range pc.members {
+		v, ok := pv[m.key]
+		if !ok {
+			continue
+		}
+		// a key may be duplicated
+		delete(pv, m.key)
+		if _, exists := last[m.key]; exists || v.IsNull() {
+			continue
+		}
+		nv, err := mergePatchJSON(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			res = append(res, ',')
+		}
+		n++
+		res = appendQuote(res, m.key)
+		res = append(res, ':')
+		res = append(res, nv...)
+	}
+	if n > 0 && len(tc.members) > 0 {
+		res = append(res, target[tc.members[len(tc.members)-1].span.end:interior.end]...)
+	}
+	return append(res, '}'), nil
+}
+
+// compact returns d without insignificant whitespace
+func compact(d JSON) (JSON, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CreateMergePatch returns the minimal JSON Merge Patch (RFC 7386) which
+// transforms original into modified.
+//
+// Members removed from original are set to null in the patch. As null is the
+// delete marker, members of modified which are explicitly null can not be
+// represented and are removed instead.
+func CreateMergePatch(original, modified JSON) (JSON, error) {
+	if err := original.Validate(); err != nil {
+		return nil, err
+	}
+	if err := modified.Validate(); err != nil {
+		return nil, err
+	}
+	patch, err := createMergePatch(original.trimSpace(), modified.trimSpace())
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, patch); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func createMergePatch(original, modified JSON) (JSON, error) {
+	if !original.IsObject() || !modified.IsObject() {
+		return modified, nil
+	}
+	oc, err := scanContainer(original, 0)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := scanContainer(modified, 0)
+	if err != nil {
+		return nil, err
+	}
+	ov := make(map[string]JSON, len(oc.members))
+	for _, m := range oc.members {
+		ov[m.key] = original[m.value.start:m.value.end]
+	}
+	mv := make(map[string]JSON, len(mc.members))
+	patch := JSON{'{'}
+	add := func(key string, value []byte) {
+		if len(patch) > 1 {
+			patch = append(patch, ',')
+		}
+		patch = appendQuote(patch, key)
+		patch = append(patch, ':')
+		patch = append(patch, value...)
+	}
+	for _, m := range mc.members {
+		mv[m.key] = modified[m.value.start:m.value.end]
+	}
+	for _, m := range oc.members {
+		if _, ok := mv[m.key]; !ok {
+			add(m.key, Null)
+			// a key may be duplicated
+			mv[m.key] = nil
+		}
+	}
+	for _, m := range mc.members {
+		v := mv[m.key]
+		if v == nil {
+			continue
+		}
+		// only the last of any duplicate keys is considered
+		mv[m.key] = nil
+		o, exists := ov[m.key]
+		switch {
+		case v.IsNull():
+			if exists && !o.IsNull() {
+				add(m.key, Null)
+			}
+		case !exists:
+			add(m.key, v)
+		case o.IsObject() && v.IsObject():
+			p, err := createMergePatch(o, v)
+			if err != nil {
+				return nil, err
+			}
+			if !p.IsEmptyObject() {
+				add(m.key, p)
+			}
+		default:
+			eq, err := equalJSON(o, v)
+			if err != nil {
+				return nil, err
+			}
+			if !eq {
+				add(m.key, v)
+			}
+		}
+	}
+	return append(patch, '}'), nil
+}
+
+// MergePatch applies the JSON Merge Patch (RFC 7386) patch to m. patch must
+// be an object and can be a Map, a map[string]interface{}, JSON, a
+// json.RawMessage or []byte.
+//
+// Members of patch which are nil or a null JSON value, such as dynamic.Null,
+// are removed from m.
+func (m Map) MergePatch(patch interface{}) error {
+	p, err := mergePatchObject(patch)
+	if err != nil {
+		return err
+	}
+	for k, pv := range p {
+		if isNullValue(pv) {
+			delete(m, k)
+			continue
+		}
+		nv, err := mergePatchValue(m[k], pv)
+		if err != nil {
+			return err
+		}
+		m[k] = nv
+	}
+	return nil
+}
+
+// CreateMapMergePatch returns the minimal JSON Merge Patch (RFC 7386) which
+// transforms original into modified. Removed members are set to dynamic.Null.
+func CreateMapMergePatch(original, modified Map) (Map, error) {
+	o, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	m, err := json.Marshal(modified)
+	if err != nil {
+		return nil, err
+	}
+	p, err := createMergePatch(o, m)
+	if err != nil {
+		return nil, err
+	}
+	v, err := decode(p)
+	if err != nil {
+		return nil, err
+	}
+	patch := Map(v.(map[string]interface{}))
+	replaceNulls(patch)
+	return patch, nil
+}
+
+func replaceNulls(obj map[string]interface{}) {
+	for k, v := range obj {
+		if v == nil {
+			obj[k] = Null
+		} else if o, ok := v.(map[string]interface{}); ok {
+			replaceNulls(o)
+		}
+	}
+}
+
+// isNullValue reports whether v is nil or a null JSON value
+func isNullValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if raw, ok := asRawJSON(v); ok {
+		return raw.IsNull()
+	}
+	return false
+}
+
+// mergePatchObject returns patch as an object, decoding it if necessary
+func mergePatchObject(patch interface{}) (map[string]interface{}, error) {
+	if obj, ok := asObject(patch); ok {
+		return obj, nil
+	}
+	var data []byte
+	switch p := patch.(type) {
+	case JSON:
+		data = p
+	case json.RawMessage:
+		data = p
+	case []byte:
+		data = p
+	default:
+		return nil, fmt.Errorf("%w: %T can not be used as a merge patch", ErrInvalidType, patch)
+	}
+	if err := JSON(data).Validate(); err != nil {
+		return nil, err
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: merge patch must be an object", ErrInvalidValue)
+	}
+	return obj, nil
+}
+
+func mergePatchValue(target interface{}, patch interface{}) (interface{}, error) {
+	if raw, ok := asRawJSON(target); ok {
+		p, err := json.Marshal(patch)
+		if err != nil {
+			return nil, err
+		}
+		return raw.MergePatch(p)
+	}
+	if raw, ok := asRawJSON(patch); ok && raw.IsObject() {
+		v, err := decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		patch = v
+	}
+	p, ok := asObject(patch)
+	if !ok {
+		return patch, nil
+	}
+	obj, ok := asObject(target)
+	if !ok {
+		obj = map[string]interface{}{}
+		target = obj
+	}
+	for k, pv := range p {
+		if isNullValue(pv) {
+			delete(obj, k)
+			continue
+		}
+		nv, err := mergePatchValue(obj[k], pv)
+		if err != nil {
+			return nil, err
+		}
+		obj[k] = nv
+	}
+	return target, nil
+}
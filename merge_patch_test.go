@@ -0,0 +1,98 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONMergePatch(t *testing.T) {
+	assert := require.New(t)
+	// test cases from RFC 7386, Appendix A
+	tests := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		res, err := dynamic.JSON(test.target).MergePatch(dynamic.JSON(test.patch))
+		assert.NoError(err)
+		assert.JSONEq(test.expected, string(res), "%s + %s", test.target, test.patch)
+	}
+
+	res, err := dynamic.JSON("{\n  \"b\": 1,\n  \"a\": 2\n}").MergePatch(dynamic.JSON(`{"a":3}`))
+	assert.NoError(err)
+	assert.Equal("{\n  \"b\": 1,\n  \"a\": 3\n}", string(res))
+
+	res, err = dynamic.JSON("{\n  \"a\": 1,\n  \"b\": {\n    \"c\": 2,\n    \"d\": 3\n  },\n  \"a\": 4,\n  \"e\": 5\n}").
+		MergePatch(dynamic.JSON(`{"a": null, "b": {"d": null, "f": [1, 2]}, "g": {"h": null, "i": 6}}`))
+	assert.NoError(err)
+	assert.Equal("{\n  \"b\": {\n    \"c\": 2,\"f\":[1,2]\n  },\n  \"e\": 5,\"g\":{\"i\":6}\n}", string(res))
+
+	_, err = dynamic.JSON(`{"a":}`).MergePatch(dynamic.JSON(`{}`))
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	assert := require.New(t)
+	original := dynamic.JSON(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged","n":1}`)
+	modified := dynamic.JSON(`{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890","n":1}`)
+	patch, err := dynamic.CreateMergePatch(original, modified)
+	assert.NoError(err)
+	assert.JSONEq(`{"title":"Hello!","author":{"familyName":null},"tags":["example"],"phoneNumber":"+01-123-456-7890"}`, string(patch))
+
+	res, err := original.MergePatch(patch)
+	assert.NoError(err)
+	assert.JSONEq(string(modified), string(res))
+
+	patch, err = dynamic.CreateMergePatch(original, original)
+	assert.NoError(err)
+	assert.Equal(`{}`, string(patch))
+}
+
+func TestMapMergePatch(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{
+		"a": "b",
+		"c": map[string]interface{}{"d": "e", "f": "g"},
+		"h": dynamic.JSON(`{"i":1}`),
+	}
+	err := m.MergePatch(dynamic.Map{
+		"a": dynamic.Null,
+		"c": dynamic.Map{"f": nil, "x": 1},
+		"h": map[string]interface{}{"j": 2},
+		"k": dynamic.JSON(`{"l":null,"m":true}`),
+	})
+	assert.NoError(err)
+	data, err := json.Marshal(m)
+	assert.NoError(err)
+	assert.JSONEq(`{"c":{"d":"e","x":1},"h":{"i":1,"j":2},"k":{"m":true}}`, string(data))
+
+	assert.NoError(m.MergePatch(dynamic.JSON(`{"c":null}`)))
+	_, ok := m["c"]
+	assert.False(ok)
+	assert.ErrorIs(m.MergePatch(dynamic.JSON(`[]`)), dynamic.ErrInvalidValue)
+
+	patch, err := dynamic.CreateMapMergePatch(
+		dynamic.Map{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}},
+		dynamic.Map{"a": 1, "b": map[string]interface{}{"c": 3}},
+	)
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"b": map[string]interface{}{"c": json.Number("3"), "d": dynamic.Null}}, patch)
+}
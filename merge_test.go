@@ -0,0 +1,82 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func mustMap(t *testing.T, data string) dynamic.Map {
+	var m dynamic.Map
+	require.NoError(t, json.Unmarshal([]byte(data), &m))
+	return m
+}
+
+func TestMapMerge(t *testing.T) {
+	assert := require.New(t)
+	defaults := mustMap(t, `{
+		"settings": {"shards": 1, "replicas": 1, "analysis": {"filters": ["lowercase"]}},
+		"fields": [{"name": "id", "type": "keyword"}, {"name": "title", "type": "text"}],
+		"tags": ["a", "b"],
+		"owner": {"name": "ops"}
+	}`)
+	overrides := mustMap(t, `{
+		"settings": {"shards": 3, "replicas": 1, "analysis": {"filters": ["asciifolding"]}, "refresh": "1s"},
+		"fields": [{"name": "title", "type": "keyword", "store": true}, {"name": "body", "type": "text"}],
+		"tags": ["b", "c"],
+		"owner": "platform"
+	}`)
+	conflicts, err := defaults.Merge(overrides, dynamic.MergeOptions{
+		Paths: map[string]dynamic.MergeStrategy{
+			"/settings/analysis/filters": {Arrays: dynamic.ArrayAppend},
+			"/fields":                    {Arrays: dynamic.ArrayUnion, UnionKey: "name"},
+			"/tags":                      {Arrays: dynamic.ArrayUnion},
+			"/owner":                     {Conflicts: dynamic.ConflictLeft},
+		},
+	})
+	assert.NoError(err)
+
+	res, err := json.Marshal(defaults)
+	assert.NoError(err)
+	assert.True(dynamic.JSON(`{
+		"settings": {"shards": 3, "replicas": 1, "analysis": {"filters": ["lowercase", "asciifolding"]}, "refresh": "1s"},
+		"fields": [{"name": "id", "type": "keyword"}, {"name": "title", "type": "keyword", "store": true}, {"name": "body", "type": "text"}],
+		"tags": ["a", "b", "c"],
+		"owner": {"name": "ops"}
+	}`).SemanticEqual(res), string(res))
+
+	paths := map[string]dynamic.ConflictStrategy{}
+	for _, c := range conflicts {
+		paths[c.Path] = c.Resolution
+	}
+	assert.Equal(map[string]dynamic.ConflictStrategy{
+		"/settings/shards": dynamic.ConflictRight,
+		"/fields/1/type":   dynamic.ConflictRight,
+		"/owner":           dynamic.ConflictLeft,
+	}, paths)
+}
+
+func TestMapMergeReplaceAndError(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{"list": []interface{}{1, 2}, "raw": dynamic.JSON(`{"a": 1}`)}
+	conflicts, err := m.Merge(dynamic.Map{"list": []interface{}{3}, "raw": dynamic.Map{"b": 2}}, dynamic.MergeOptions{})
+	assert.NoError(err)
+	assert.Empty(conflicts)
+	assert.Equal([]interface{}{3}, m["list"])
+	res, err := json.Marshal(m["raw"])
+	assert.NoError(err)
+	assert.JSONEq(`{"a": 1, "b": 2}`, string(res))
+
+	m = dynamic.Map{"a": dynamic.Map{"b": 1, "c": 1}}
+	conflicts, err = m.Merge(dynamic.Map{"a": dynamic.Map{"b": 1.0, "c": 2}}, dynamic.MergeOptions{
+		Default: dynamic.MergeStrategy{Conflicts: dynamic.ConflictError},
+	})
+	assert.ErrorIs(err, dynamic.ErrMergeConflict)
+	assert.Len(conflicts, 1)
+	assert.Equal("/a/c", conflicts[0].Path)
+	assert.Equal(1, conflicts[0].Left)
+	assert.Equal(2, conflicts[0].Right)
+	assert.Equal(dynamic.Map{"a": dynamic.Map{"b": 1, "c": 1}}, m)
+}
@@ -0,0 +1,252 @@
+package dynamic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// recordSeparator begins each record of a JSON Text Sequence (RFC 7464)
+const recordSeparator = 0x1E
+
+// NDJSONError is returned when a record of newline delimited json or a JSON
+// Text Sequence can not be read or decoded.
+type NDJSONError struct {
+	// Line is the line, starting at 1, on which the record begins
+	Line int
+	Err  error
+}
+
+func (e *NDJSONError) Error() string {
+	return fmt.Sprintf("dynamic: record on line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONReader reads newline delimited json records. Blank lines are
+// skipped.
+//
+// If the input begins with a record separator (0x1E), it is read as a JSON
+// Text Sequence (RFC 7464) instead; each record begins with a record
+// separator and may span multiple lines.
+type NDJSONReader struct {
+	r     *bufio.Reader
+	line  int
+	start int
+	seq   *bool
+}
+
+// NewNDJSONReader returns a new NDJSONReader which reads from r
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	return &NDJSONReader{r: bufio.NewReader(r), line: 1}
+}
+
+// Line returns the line number on which the last record read begins
+func (r *NDJSONReader) Line() int {
+	return r.start
+}
+
+// Read returns the next record. Each record is validated; a *NDJSONError is
+// returned if it is not valid json. io.EOF is returned when there are no more
+// records.
+func (r *NDJSONReader) Read() (JSON, error) {
+	rec, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if err := rec.Validate(); err != nil {
+		return nil, &NDJSONError{Line: r.start, Err: err}
+	}
+	return rec, nil
+}
+
+// next returns the next record without validating it
+func (r *NDJSONReader) next() (JSON, error) {
+	if r.seq == nil {
+		if err := r.detect(); err != nil {
+			return nil, err
+		}
+	}
+	delim := byte('\n')
+	if *r.seq {
+		delim = recordSeparator
+	}
+	for {
+		data, err := r.r.ReadBytes(delim)
+		if err != nil && err != io.EOF {
+			return nil, &NDJSONError{Line: r.line, Err: err}
+		}
+		r.start = r.line
+		for i := 0; i < len(data) && isSpace(data[i]); i++ {
+			if data[i] == '\n' {
+				r.start++
+			}
+		}
+		r.line += bytes.Count(data, []byte{'\n'})
+		rec := JSON(bytes.TrimSuffix(data, []byte{delim})).trimSpace()
+		if len(rec) > 0 {
+			return rec, nil
+		}
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
+
+// detect determines whether the input is a JSON Text Sequence
+func (r *NDJSONReader) detect() error {
+	seq := false
+	for {
+		b, err := r.r.Peek(1)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return &NDJSONError{Line: r.line, Err: err}
+		}
+		if !isSpace(b[0]) {
+			seq = b[0] == recordSeparator
+			break
+		}
+		if b[0] == '\n' {
+			r.line++
+		}
+		r.r.ReadByte()
+	}
+	if seq {
+		r.r.ReadByte()
+	}
+	r.seq = &seq
+	return nil
+}
+
+// Decode reads the remaining records, decoding each into the value returned
+// from newValue, which should be a pointer, across workers goroutines. fn is
+// called with each decoded value and the line it begins on, in the order the
+// records were read.
+//
+// If workers is less than 1, runtime.GOMAXPROCS(0) is used. If fn returns
+// Done, decoding stops and nil is returned. Errors reading or decoding a
+// record are returned as a *NDJSONError.
+func (r *NDJSONReader) Decode(workers int, newValue func() interface{}, fn func(line int, v interface{}) error) error {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	type result struct {
+		v   interface{}
+		err error
+	}
+	type job struct {
+		line int
+		data JSON
+		res  chan result
+	}
+	jobs := make(chan job)
+	order := make(chan job, workers*2)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(order)
+		defer close(jobs)
+		for {
+			data, err := r.next()
+			if err == io.EOF {
+				return
+			}
+			j := job{line: r.start, data: data, res: make(chan result, 1)}
+			if err != nil {
+				j.res <- result{err: err}
+				select {
+				case order <- j:
+				case <-done:
+				}
+				return
+			}
+			select {
+			case order <- j:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-done:
+				return
+			}
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				v := newValue()
+				err := json.Unmarshal(j.data, v)
+				j.res <- result{v: v, err: err}
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(done)
+
+	for j := range order {
+		res := <-j.res
+		if res.err != nil {
+			if _, ok := res.err.(*NDJSONError); ok {
+				return res.err
+			}
+			return &NDJSONError{Line: j.line, Err: res.err}
+		}
+		if err := fn(j.line, res.v); err != nil {
+			if err == Done {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// NDJSONWriter writes newline delimited json records or, if created with
+// NewJSONSeqWriter, a JSON Text Sequence (RFC 7464).
+type NDJSONWriter struct {
+	w   io.Writer
+	seq bool
+}
+
+// NewNDJSONWriter returns a new NDJSONWriter which writes newline delimited
+// json to w
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: w}
+}
+
+// NewJSONSeqWriter returns a new NDJSONWriter which writes a JSON Text
+// Sequence (RFC 7464) to w
+func NewJSONSeqWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: w, seq: true}
+}
+
+// Write encodes v as a single, compact record. JSON values are compacted
+// rather than re-encoded.
+func (w *NDJSONWriter) Write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 0, len(data)+2)
+	if w.seq {
+		buf = append(buf, recordSeparator)
+	}
+	buf = append(buf, data...)
+	buf = append(buf, '\n')
+	_, err = w.w.Write(buf)
+	return err
+}
@@ -0,0 +1,131 @@
+package dynamic_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNDJSONReader(t *testing.T) {
+	assert := require.New(t)
+	r := dynamic.NewNDJSONReader(strings.NewReader("{\"a\":1}\n\n  [1, 2]\r\n\"str\"\n{\"b\":\n"))
+	var records []string
+	var lines []int
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var nerr *dynamic.NDJSONError
+			assert.ErrorAs(err, &nerr)
+			assert.Equal(5, nerr.Line)
+			assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+			break
+		}
+		records = append(records, string(rec))
+		lines = append(lines, r.Line())
+	}
+	assert.Equal([]string{`{"a":1}`, `[1, 2]`, `"str"`}, records)
+	assert.Equal([]int{1, 3, 4}, lines)
+
+	r = dynamic.NewNDJSONReader(strings.NewReader("\x1e{\n  \"a\": 1\n}\n\x1e[2]\n\x1e\n"))
+	records = nil
+	lines = nil
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(err)
+		records = append(records, string(rec))
+		lines = append(lines, r.Line())
+	}
+	assert.Equal([]string{"{\n  \"a\": 1\n}", `[2]`}, records)
+	assert.Equal([]int{1, 4}, lines)
+}
+
+func TestNDJSONReaderDecode(t *testing.T) {
+	assert := require.New(t)
+	type rec struct {
+		N int `json:"n"`
+	}
+	var input strings.Builder
+	for i := 0; i < 500; i++ {
+		input.WriteString(`{"n":`)
+		input.WriteString(strings.Repeat("1", 1+i%3))
+		input.WriteString("}\n")
+	}
+	var values []int
+	err := dynamic.NewNDJSONReader(strings.NewReader(input.String())).Decode(4, func() interface{} {
+		return &rec{}
+	}, func(line int, v interface{}) error {
+		assert.Equal(len(values)+1, line)
+		values = append(values, v.(*rec).N)
+		return nil
+	})
+	assert.NoError(err)
+	assert.Len(values, 500)
+	for i, v := range values {
+		assert.Equal([]int{1, 11, 111}[i%3], v)
+	}
+
+	count := 0
+	err = dynamic.NewNDJSONReader(strings.NewReader(input.String())).Decode(0, func() interface{} {
+		return &rec{}
+	}, func(line int, v interface{}) error {
+		count++
+		if count == 10 {
+			return dynamic.Done
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(10, count)
+
+	err = dynamic.NewNDJSONReader(strings.NewReader("{\"n\":1}\n{\"n\":\"x\"}\n{\"n\":3}\n")).Decode(2, func() interface{} {
+		return &rec{}
+	}, func(line int, v interface{}) error {
+		return nil
+	})
+	var nerr *dynamic.NDJSONError
+	assert.ErrorAs(err, &nerr)
+	assert.Equal(2, nerr.Line)
+
+	err = dynamic.NewNDJSONReader(strings.NewReader("{\"n\":1}\n")).Decode(2, func() interface{} {
+		return &rec{}
+	}, func(line int, v interface{}) error {
+		return errors.New("some error")
+	})
+	assert.EqualError(err, "some error")
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	assert := require.New(t)
+	var buf bytes.Buffer
+	w := dynamic.NewNDJSONWriter(&buf)
+	assert.NoError(w.Write(dynamic.JSON("{\n  \"a\": 1\n}")))
+	assert.NoError(w.Write([]int{1, 2}))
+	assert.Equal("{\"a\":1}\n[1,2]\n", buf.String())
+
+	buf.Reset()
+	w = dynamic.NewJSONSeqWriter(&buf)
+	assert.NoError(w.Write("a"))
+	assert.NoError(w.Write(nil))
+	assert.Equal("\x1e\"a\"\n\x1enull\n", buf.String())
+
+	r := dynamic.NewNDJSONReader(&buf)
+	rec, err := r.Read()
+	assert.NoError(err)
+	assert.Equal(`"a"`, string(rec))
+	rec, err = r.Read()
+	assert.NoError(err)
+	assert.Equal(`null`, string(rec))
+	_, err = r.Read()
+	assert.Equal(io.EOF, err)
+}
@@ -0,0 +1,271 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// keyOrder tracks the order in which keys were added to an object
+type keyOrder []string
+
+func (o keyOrder) index(key string) int {
+	for i, k := range o {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *keyOrder) remove(key string) {
+	if i := o.index(key); i >= 0 {
+		*o = append((*o)[:i], (*o)[i+1:]...)
+	}
+}
+
+// OrderedObject is a json object of JSON values which retains the order of its
+// keys. Keys are kept in the order in which they were first set or, when
+// unmarshaled, the order in which they appear on the wire. The zero value is
+// an empty object ready to use.
+type OrderedObject struct {
+	keys   keyOrder
+	values map[string]JSON
+}
+
+// Len returns the number of members of obj
+func (obj *OrderedObject) Len() int {
+	return len(obj.keys)
+}
+
+// Get returns the value of key and whether it exists
+func (obj *OrderedObject) Get(key string) (JSON, bool) {
+	v, ok := obj.values[key]
+	return v, ok
+}
+
+// Set sets the value of key. New keys are added to the end of obj; existing
+// keys retain their position.
+func (obj *OrderedObject) Set(key string, value JSON) {
+	if obj.values == nil {
+		obj.values = map[string]JSON{}
+	}
+	if _, ok := obj.values[key]; !ok {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.values[key] = value
+}
+
+// Delete removes key from obj
+func (obj *OrderedObject) Delete(key string) {
+	if _, ok := obj.values[key]; !ok {
+		return
+	}
+	delete(obj.values, key)
+	obj.keys.remove(key)
+}
+
+// Keys returns the keys of obj in order
+func (obj *OrderedObject) Keys() []string {
+	return append([]string{}, obj.keys...)
+}
+
+// Iterate calls fn with each member of obj in order. If fn returns Done,
+// iteration stops and nil is returned. Any other error returned from fn is
+// passed along.
+func (obj *OrderedObject) Iterate(fn func(key string, v JSON) error) error {
+	for _, k := range obj.Keys() {
+		v, ok := obj.values[k]
+		if !ok {
+			continue
+		}
+		if err := fn(k, v); err != nil {
+			if err == Done {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (obj OrderedObject) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, k := range obj.keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendQuote(buf, k)
+		buf = append(buf, ':')
+		v, err := obj.values[k].MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, v...)
+	}
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON decodes data, retaining the order of its keys. If a key is
+// duplicated, the last value is kept in the position of the first.
+func (obj *OrderedObject) UnmarshalJSON(data []byte) error {
+	*obj = OrderedObject{}
+	d := JSON(data)
+	if d.IsNull() {
+		return nil
+	}
+	return d.EachMember(func(key string, v JSON) error {
+		obj.Set(key, append(JSON{}, v...))
+		return nil
+	})
+}
+
+// OrderedMap is a json object of arbitrary values which retains the order of
+// its keys. Keys are kept in the order in which they were first set or, when
+// unmarshaled, the order in which they appear on the wire. The zero value is
+// an empty object ready to use.
+//
+// When unmarshaled, nested objects are decoded as *OrderedMap, arrays as
+// []interface{} and numbers as json.Number.
+type OrderedMap struct {
+	keys   keyOrder
+	values map[string]interface{}
+}
+
+// Len returns the number of members of m
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Get returns the value of key and whether it exists
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Set sets the value of key. New keys are added to the end of m; existing
+// keys retain their position.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes key from m
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	m.keys.remove(key)
+}
+
+// Keys returns the keys of m in order
+func (m *OrderedMap) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+// Iterate calls fn with each member of m in order. If fn returns Done,
+// iteration stops and nil is returned. Any other error returned from fn is
+// passed along.
+func (m *OrderedMap) Iterate(fn func(key string, v interface{}) error) error {
+	for _, k := range m.Keys() {
+		v, ok := m.values[k]
+		if !ok {
+			continue
+		}
+		if err := fn(k, v); err != nil {
+			if err == Done {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Map returns the members of m as a Map. Nested *OrderedMap values are not
+// converted.
+func (m *OrderedMap) Map() Map {
+	res := make(Map, len(m.values))
+	for k, v := range m.values {
+		res[k] = v
+	}
+	return res
+}
+
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, k := range m.keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendQuote(buf, k)
+		buf = append(buf, ':')
+		v, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, v...)
+	}
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON decodes data, retaining the order of its keys. If a key is
+// duplicated, the last value is kept in the position of the first.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	*m = OrderedMap{}
+	d := JSON(data)
+	if d.IsNull() {
+		return nil
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	return m.decode(d)
+}
+
+func (m *OrderedMap) decode(d JSON) error {
+	return d.EachMember(func(key string, v JSON) error {
+		value, err := decodeOrdered(v)
+		if err != nil {
+			return err
+		}
+		m.Set(key, value)
+		return nil
+	})
+}
+
+// decodeOrdered decodes d into generic values, decoding objects as
+// *OrderedMap
+func decodeOrdered(d JSON) (interface{}, error) {
+	switch k := d.Kind(); k {
+	case KindObject:
+		m := &OrderedMap{}
+		if err := m.decode(d); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case KindArray:
+		s := []interface{}{}
+		err := d.EachElement(func(i int, v JSON) error {
+			value, err := decodeOrdered(v)
+			if err != nil {
+				return err
+			}
+			s = append(s, value)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case KindInvalid:
+		return nil, fmt.Errorf("%w: invalid json", ErrMalformedJSON)
+	default:
+		return decode(d)
+	}
+}
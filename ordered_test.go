@@ -0,0 +1,79 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedObject(t *testing.T) {
+	assert := require.New(t)
+	var obj dynamic.OrderedObject
+	assert.NoError(json.Unmarshal([]byte(`{"z": 1, "a": {"y": 2, "b": 3}, "m": [1, 2], "a": "dup"}`), &obj))
+	assert.Equal([]string{"z", "a", "m"}, obj.Keys())
+	v, ok := obj.Get("a")
+	assert.True(ok)
+	assert.Equal(`"dup"`, string(v))
+
+	obj.Set("b", dynamic.JSON(`true`))
+	obj.Set("z", dynamic.JSON(`0`))
+	obj.Delete("m")
+	obj.Delete("missing")
+	assert.Equal(3, obj.Len())
+
+	data, err := json.Marshal(obj)
+	assert.NoError(err)
+	assert.Equal(`{"z":0,"a":"dup","b":true}`, string(data))
+
+	var keys []string
+	err = obj.Iterate(func(key string, v dynamic.JSON) error {
+		keys = append(keys, key)
+		if key == "a" {
+			return dynamic.Done
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"z", "a"}, keys)
+
+	assert.Error(json.Unmarshal([]byte(`[1]`), &obj))
+}
+
+func TestOrderedMap(t *testing.T) {
+	assert := require.New(t)
+	var m dynamic.OrderedMap
+	data := `{"z":1,"a":{"y":2,"b":[{"d":null,"c":1.50}]},"m":"str"}`
+	assert.NoError(json.Unmarshal([]byte(data), &m))
+	assert.Equal([]string{"z", "a", "m"}, m.Keys())
+
+	v, ok := m.Get("z")
+	assert.True(ok)
+	assert.Equal(json.Number("1"), v)
+
+	v, ok = m.Get("a")
+	assert.True(ok)
+	nested, ok := v.(*dynamic.OrderedMap)
+	assert.True(ok)
+	assert.Equal([]string{"y", "b"}, nested.Keys())
+
+	res, err := json.Marshal(m)
+	assert.NoError(err)
+	assert.Equal(data, string(res))
+
+	m.Delete("z")
+	m.Set("n", []int{1})
+	m.Set("m", nil)
+	res, err = json.Marshal(&m)
+	assert.NoError(err)
+	assert.Equal(`{"a":{"y":2,"b":[{"d":null,"c":1.50}]},"m":null,"n":[1]}`, string(res))
+	assert.Len(m.Map(), 3)
+
+	var zero dynamic.OrderedMap
+	res, err = json.Marshal(zero)
+	assert.NoError(err)
+	assert.Equal(`{}`, string(res))
+
+	assert.Error(json.Unmarshal([]byte(`{"a":}`), &m))
+}
@@ -0,0 +1,306 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPatchTestFailed  = errors.New("dynamic: json patch test failed")
+	ErrInvalidOperation = errors.New("dynamic: invalid json patch operation")
+)
+
+// JSON Patch operations
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// PatchOperation is a single operation of a JSON Patch (RFC 6902)
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value JSON   `json:"value,omitempty"`
+}
+
+func (op PatchOperation) String() string {
+	switch op.Op {
+	case OpRemove:
+		return fmt.Sprintf("%s %s", op.Op, op.Path)
+	case OpMove, OpCopy:
+		return fmt.Sprintf("%s %s -> %s", op.Op, op.From, op.Path)
+	default:
+		return fmt.Sprintf("%s %s: %s", op.Op, op.Path, op.Value)
+	}
+}
+
+// Patch is a JSON Patch (RFC 6902) document
+type Patch []PatchOperation
+
+// String renders p in a human readable form, one operation per line, for
+// logging purposes.
+func (p Patch) String() string {
+	lines := make([]string, len(p))
+	for i, op := range p {
+		lines[i] = op.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PatchError is returned when an operation of a Patch can not be applied
+type PatchError struct {
+	// Index is the position of the operation within the Patch
+	Index     int
+	Operation PatchOperation
+	Err       error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("dynamic: json patch operation %d (%s) failed: %v", e.Index, e.Operation, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyPatch applies the JSON Patch (RFC 6902) p to d, returning the result.
+// If any operation fails, a *PatchError is returned and d is left unmodified.
+func (d JSON) ApplyPatch(p Patch) (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	doc := d
+	for i, op := range p {
+		var err error
+		if doc, err = applyOperation(doc, op); err != nil {
+			return nil, &PatchError{Index: i, Operation: op, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+// ApplyPatch applies the JSON Patch (RFC 6902) p to m. The result must be an
+// object. If any operation fails, a *PatchError is returned and m is left
+// unmodified.
+//
+// m is encoded to json in order to apply p and its values are replaced with
+// the decoded result. Numbers are decoded as json.Number.
+func (m Map) ApplyPatch(p Patch) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	res, err := JSON(data).ApplyPatch(p)
+	if err != nil {
+		return err
+	}
+	v, err := decode(res)
+	if err != nil {
+		return err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: result of json patch is not an object", ErrInvalidValue)
+	}
+	for k := range m {
+		delete(m, k)
+	}
+	for k, v := range obj {
+		m[k] = v
+	}
+	return nil
+}
+
+func applyOperation(doc JSON, op PatchOperation) (JSON, error) {
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		// RFC 6902, section 4
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("%w: %s requires a value", ErrInvalidOperation, op.Op)
+		}
+	}
+	switch op.Op {
+	case OpAdd:
+		return addPointer(doc, op.Path, op.Value)
+	case OpRemove:
+		return doc.DeletePointer(op.Path)
+	case OpReplace:
+		if _, err := doc.Pointer(op.Path); err != nil {
+			return nil, err
+		}
+		return doc.SetPointer(op.Path, op.Value)
+	case OpMove:
+		if op.From == op.Path {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("%w: can not move %q into one of its children", ErrInvalidOperation, op.From)
+		}
+		v, err := doc.Pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v = append(JSON{}, v...)
+		if doc, err = doc.DeletePointer(op.From); err != nil {
+			return nil, err
+		}
+		return addPointer(doc, op.Path, v)
+	case OpCopy:
+		v, err := doc.Pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		return addPointer(doc, op.Path, append(JSON{}, v...))
+	case OpTest:
+		v, err := doc.Pointer(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		eq, err := equalJSON(v, op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !eq {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, op.Op)
+	}
+}
+
+// addPointer performs the add operation of JSON Patch which, unlike
+// SetPointer, inserts values into arrays rather than replacing them.
+func addPointer(doc JSON, ptr string, value JSON) (JSON, error) {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return doc.SetPointer(ptr, value)
+	}
+	parent, err := doc.locate(ptr, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	c, err := scanContainer(doc, parent.start)
+	if err != nil {
+		return nil, err
+	}
+	if c.kind == KindArray {
+		if i, ok := parseIndex(tokens[len(tokens)-1]); ok && i < len(c.members) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			at := c.members[i].span.start
+			return splice(doc, span{start: at, end: at}, append(data, ',')), nil
+		}
+	}
+	return doc.SetPointer(ptr, value)
+}
+
+// Diff returns a JSON Patch (RFC 6902) which transforms a into b. Object
+// members are compared in sorted order, arrays are compared by index.
+func Diff(a, b JSON) (Patch, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	av, err := decode(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return diff(Patch{}, nil, av, bv)
+}
+
+func diff(p Patch, path []string, a, b interface{}) (Patch, error) {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(at)+len(bt))
+		for k := range at {
+			keys = append(keys, k)
+		}
+		for k := range bt {
+			if _, ok := at[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var err error
+		for _, k := range keys {
+			av, inA := at[k]
+			bv, inB := bt[k]
+			kp := childPath(path, k)
+			switch {
+			case !inB:
+				p = append(p, PatchOperation{Op: OpRemove, Path: FormatPointer(kp...)})
+			case !inA:
+				if p, err = appendValueOp(p, OpAdd, kp, bv); err != nil {
+					return nil, err
+				}
+			default:
+				if p, err = diff(p, kp, av, bv); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return p, nil
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		var err error
+		for i := 0; i < len(at) && i < len(bt); i++ {
+			if p, err = diff(p, childPath(path, strconv.Itoa(i)), at[i], bt[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i := len(at); i < len(bt); i++ {
+			if p, err = appendValueOp(p, OpAdd, childPath(path, strconv.Itoa(i)), bt[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i := len(at) - 1; i >= len(bt); i-- {
+			p = append(p, PatchOperation{Op: OpRemove, Path: FormatPointer(childPath(path, strconv.Itoa(i))...)})
+		}
+		return p, nil
+	}
+	if equalValues(a, b) {
+		return p, nil
+	}
+	return appendValueOp(p, OpReplace, path, b)
+}
+
+func appendValueOp(p Patch, op string, path []string, value interface{}) (Patch, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append(p, PatchOperation{Op: op, Path: FormatPointer(path...), Value: data}), nil
+}
+
+// childPath returns a copy of path with token appended
+func childPath(path []string, token string) []string {
+	return append(path[:len(path):len(path)], token)
+}
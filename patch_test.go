@@ -0,0 +1,104 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONApplyPatch(t *testing.T) {
+	assert := require.New(t)
+	tests := []struct {
+		doc, patch, expected string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"a":1}}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"foo":{"a":1},"bar":{"a":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{`{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`},
+	}
+	for _, test := range tests {
+		var p dynamic.Patch
+		assert.NoError(json.Unmarshal([]byte(test.patch), &p))
+		res, err := dynamic.JSON(test.doc).ApplyPatch(p)
+		assert.NoError(err, test.patch)
+		assert.JSONEq(test.expected, string(res), test.patch)
+	}
+
+	failures := []struct {
+		doc, patch string
+		err        error
+	}{
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, dynamic.ErrPatchTestFailed},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, dynamic.ErrPointerNotFound},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"qux"}]`, dynamic.ErrPointerNotFound},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, dynamic.ErrInvalidOperation},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/foo"}]`, dynamic.ErrInvalidOperation},
+		{`{"foo":"bar"}`, `[{"op":"test","path":"/foo"}]`, dynamic.ErrInvalidOperation},
+		{`{"foo":{"a":1}}`, `[{"op":"move","from":"/foo","path":"/foo/b"}]`, dynamic.ErrInvalidOperation},
+		{`{"foo":"bar"}`, `[{"op":"nope","path":"/foo"}]`, dynamic.ErrInvalidOperation},
+	}
+	for _, test := range failures {
+		var p dynamic.Patch
+		assert.NoError(json.Unmarshal([]byte(test.patch), &p))
+		_, err := dynamic.JSON(test.doc).ApplyPatch(p)
+		assert.ErrorIs(err, test.err, test.patch)
+		var perr *dynamic.PatchError
+		assert.ErrorAs(err, &perr)
+		assert.Equal(0, perr.Index)
+	}
+}
+
+func TestMapApplyPatch(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{"a": []string{"x"}, "b": 1}
+	err := m.ApplyPatch(dynamic.Patch{
+		{Op: dynamic.OpAdd, Path: "/a/0", Value: dynamic.JSON(`"w"`)},
+		{Op: dynamic.OpRemove, Path: "/b"},
+	})
+	assert.NoError(err)
+	assert.Equal(dynamic.Map{"a": []interface{}{"w", "x"}}, m)
+
+	err = m.ApplyPatch(dynamic.Patch{{Op: dynamic.OpRemove, Path: "/c"}})
+	assert.ErrorIs(err, dynamic.ErrPointerNotFound)
+	assert.Equal(dynamic.Map{"a": []interface{}{"w", "x"}}, m)
+}
+
+func TestDiff(t *testing.T) {
+	assert := require.New(t)
+	a := dynamic.JSON(`{"a":1,"b":{"c":[1,2,3],"d":"e"},"f":true}`)
+	b := dynamic.JSON(`{"a":2,"b":{"c":[1,4],"g":null},"h":[1]}`)
+	p, err := dynamic.Diff(a, b)
+	assert.NoError(err)
+	assert.Equal(`replace /a: 2
+replace /b/c/1: 4
+remove /b/c/2
+remove /b/d
+add /b/g: null
+remove /f
+add /h: [1]`, p.String())
+
+	res, err := a.ApplyPatch(p)
+	assert.NoError(err)
+	assert.JSONEq(string(b), string(res))
+
+	p, err = dynamic.Diff(dynamic.JSON(`[1]`), dynamic.JSON(`[1,2,3]`))
+	assert.NoError(err)
+	res, err = dynamic.JSON(`[1]`).ApplyPatch(p)
+	assert.NoError(err)
+	assert.JSONEq(`[1,2,3]`, string(res))
+
+	p, err = dynamic.Diff(a, a)
+	assert.NoError(err)
+	assert.Empty(p)
+}
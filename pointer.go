@@ -0,0 +1,298 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPointer  = errors.New("dynamic: invalid json pointer")
+	ErrPointerNotFound = errors.New("dynamic: json pointer not found")
+)
+
+// PointerError is returned when a JSON Pointer (RFC 6901) can not be parsed
+// or resolved.
+type PointerError struct {
+	// Pointer is the JSON Pointer being resolved
+	Pointer string
+	// Token is the reference token which could not be resolved, if any
+	Token string
+	// Err is either ErrInvalidPointer or ErrPointerNotFound
+	Err error
+}
+
+func (e *PointerError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%v: %q", e.Err, e.Pointer)
+	}
+	return fmt.Sprintf("%v: %q (token %q)", e.Err, e.Pointer, e.Token)
+}
+
+func (e *PointerError) Unwrap() error {
+	return e.Err
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// ParsePointer parses the JSON Pointer ptr (RFC 6901) into its unescaped
+// reference tokens. The empty pointer, which references the whole document,
+// results in no tokens.
+func ParsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	}
+	if ptr[0] != '/' {
+		return nil, &PointerError{Pointer: ptr, Err: ErrInvalidPointer}
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || (t[j+1] != '0' && t[j+1] != '1')) {
+				return nil, &PointerError{Pointer: ptr, Token: t, Err: ErrInvalidPointer}
+			}
+		}
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return tokens, nil
+}
+
+// FormatPointer returns the JSON Pointer made up of tokens, escaping "~" and
+// "/" as necessary.
+func FormatPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(t))
+	}
+	return b.String()
+}
+
+// parseIndex parses an array index reference token. Leading zeros are not
+// permitted.
+func parseIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(token); i++ {
+		if !isDigit(token[i]) {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(token)
+	return i, err == nil
+}
+
+// member is an object member or array element located within raw json
+type member struct {
+	key   string
+	span  span // the key, if an object member, through the end of the value
+	value span
+}
+
+// container is an object or array located within raw json along with its
+// members or elements.
+type container struct {
+	span    span
+	kind    Kind
+	members []member
+}
+
+// interior returns the span between the container's delimiters
+func (c container) interior() span {
+	return span{start: c.span.start + 1, end: c.span.end - 1}
+}
+
+// find returns the index of the member for token. Objects with duplicate keys
+// resolve to the last member, matching encoding/json.
+func (c container) find(token string) (int, bool) {
+	if c.kind == KindArray {
+		i, ok := parseIndex(token)
+		return i, ok && i < len(c.members)
+	}
+	for i := len(c.members) - 1; i >= 0; i-- {
+		if c.members[i].key == token {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// removal returns the span which must be removed from the raw json to
+// delete the i-th member, including a separating comma.
+func (c container) removal(i int) span {
+	switch {
+	case len(c.members) == 1:
+		return c.interior()
+	case i < len(c.members)-1:
+		return span{start: c.members[i].span.start, end: c.members[i+1].span.start}
+	default:
+		return span{start: c.members[i-1].span.end, end: c.members[i].span.end}
+	}
+}
+
+// scanContainer scans the object or array starting at start
+func scanContainer(data JSON, start int) (container, error) {
+	s := newScanner(data)
+	s.pos = start
+	c := container{span: span{start: start}}
+	var err error
+	switch s.peek() {
+	case '{':
+		c.kind = KindObject
+		err = s.scanMembers(func(k span, v span) error {
+			key, err := unquote(data[k.start:k.end])
+			if err != nil {
+				return err
+			}
+			c.members = append(c.members, member{key: key, span: span{start: k.start, end: v.end}, value: v})
+			return nil
+		})
+	case '[':
+		c.kind = KindArray
+		err = s.scanElements(func(i int, v span) error {
+			c.members = append(c.members, member{span: v, value: v})
+			return nil
+		})
+	default:
+		return c, nil
+	}
+	c.span.end = s.pos
+	return c, err
+}
+
+// locate returns the span of the value referenced by tokens
+func (d JSON) locate(ptr string, tokens []string) (span, error) {
+	s := newScanner(d)
+	s.skipSpace()
+	v := span{start: s.pos}
+	if err := s.scanValue(); err != nil {
+		return v, err
+	}
+	v.end = s.pos
+	for _, token := range tokens {
+		c, err := scanContainer(d, v.start)
+		if err != nil {
+			return v, err
+		}
+		i, ok := c.find(token)
+		if !ok {
+			return v, &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+		}
+		v = c.members[i].value
+	}
+	return v, nil
+}
+
+func splice(data JSON, sp span, value []byte) JSON {
+	res := make(JSON, 0, len(data)-(sp.end-sp.start)+len(value))
+	res = append(res, data[:sp.start]...)
+	res = append(res, value...)
+	return append(res, data[sp.end:]...)
+}
+
+// Pointer returns the value referenced by the JSON Pointer ptr (RFC 6901).
+// The returned JSON shares its underlying bytes with d.
+//
+// A *PointerError is returned if ptr is malformed or can not be resolved and a
+// *SyntaxError is returned if d is not valid json.
+func (d JSON) Pointer(ptr string) (JSON, error) {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	v, err := d.locate(ptr, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return d[v.start:v.end], nil
+}
+
+// SetPointer returns a copy of d with the value referenced by ptr set to the
+// json encoding of value. If ptr references a member of an object which does
+// not exist, it is added. Elements of arrays can be replaced by index or
+// appended by using "-" or the length of the array as the final token.
+//
+// The parent of the referenced value must exist. The formatting of d is
+// otherwise preserved.
+func (d JSON) SetPointer(ptr string, value interface{}) (JSON, error) {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return data, nil
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	parent, err := d.locate(ptr, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	c, err := scanContainer(d, parent.start)
+	if err != nil {
+		return nil, err
+	}
+	token := tokens[len(tokens)-1]
+	if i, ok := c.find(token); ok {
+		return splice(d, c.members[i].value, data), nil
+	}
+	var insert []byte
+	switch c.kind {
+	case KindObject:
+		insert = appendQuote(insert, token)
+		insert = append(insert, ':')
+	case KindArray:
+		if i, ok := parseIndex(token); token != "-" && (!ok || i != len(c.members)) {
+			return nil, &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+		}
+	default:
+		return nil, &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+	}
+	insert = append(insert, data...)
+	if len(c.members) == 0 {
+		return splice(d, c.interior(), insert), nil
+	}
+	end := c.members[len(c.members)-1].span.end
+	return splice(d, span{start: end, end: end}, append([]byte{','}, insert...)), nil
+}
+
+// DeletePointer returns a copy of d with the value referenced by ptr removed.
+// The formatting of d is otherwise preserved.
+func (d JSON) DeletePointer(ptr string) (JSON, error) {
+	tokens, err := ParsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, &PointerError{Pointer: ptr, Err: ErrInvalidPointer}
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	parent, err := d.locate(ptr, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	c, err := scanContainer(d, parent.start)
+	if err != nil {
+		return nil, err
+	}
+	token := tokens[len(tokens)-1]
+	i, ok := c.find(token)
+	if !ok {
+		return nil, &PointerError{Pointer: ptr, Token: token, Err: ErrPointerNotFound}
+	}
+	return splice(d, c.removal(i), nil), nil
+}
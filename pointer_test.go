@@ -0,0 +1,149 @@
+package dynamic_test
+
+import (
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePointer(t *testing.T) {
+	assert := require.New(t)
+	tokens, err := dynamic.ParsePointer("/a~1b/m~0n/0")
+	assert.NoError(err)
+	assert.Equal([]string{"a/b", "m~n", "0"}, tokens)
+	assert.Equal("/a~1b/m~0n/0", dynamic.FormatPointer(tokens...))
+
+	tokens, err = dynamic.ParsePointer("")
+	assert.NoError(err)
+	assert.Empty(tokens)
+
+	_, err = dynamic.ParsePointer("a")
+	assert.ErrorIs(err, dynamic.ErrInvalidPointer)
+	_, err = dynamic.ParsePointer("/a~2")
+	assert.ErrorIs(err, dynamic.ErrInvalidPointer)
+}
+
+func TestJSONPointer(t *testing.T) {
+	assert := require.New(t)
+	doc := dynamic.JSON(`{"a": [{"b": "c"}, 2], "x/y": {"m~n": true}, "": 0}`)
+
+	v, err := doc.Pointer("/a/0/b")
+	assert.NoError(err)
+	assert.Equal(`"c"`, string(v))
+
+	v, err = doc.Pointer("/x~1y/m~0n")
+	assert.NoError(err)
+	assert.Equal(`true`, string(v))
+
+	v, err = doc.Pointer("/")
+	assert.NoError(err)
+	assert.Equal(`0`, string(v))
+
+	v, err = doc.Pointer("")
+	assert.NoError(err)
+	assert.Equal(string(doc), string(v))
+
+	_, err = doc.Pointer("/a/2")
+	assert.ErrorIs(err, dynamic.ErrPointerNotFound)
+	var perr *dynamic.PointerError
+	assert.ErrorAs(err, &perr)
+	assert.Equal("2", perr.Token)
+
+	_, err = doc.Pointer("/a/01")
+	assert.ErrorIs(err, dynamic.ErrPointerNotFound)
+
+	_, err = dynamic.JSON(`{"a":`).Pointer("/a")
+	assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+}
+
+func TestJSONSetPointer(t *testing.T) {
+	assert := require.New(t)
+	doc := dynamic.JSON(`{"a": [1, 2], "b": {}}`)
+
+	res, err := doc.SetPointer("/a/0", "one")
+	assert.NoError(err)
+	assert.Equal(`{"a": ["one", 2], "b": {}}`, string(res))
+
+	res, err = doc.SetPointer("/a/-", 3)
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 2,3], "b": {}}`, string(res))
+
+	res, err = doc.SetPointer("/b/c", map[string]int{"d": 1})
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 2], "b": {"c":{"d":1}}}`, string(res))
+
+	res, err = doc.SetPointer("/c", dynamic.JSON(`null`))
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 2], "b": {},"c":null}`, string(res))
+	assert.True(res.Valid())
+
+	_, err = doc.SetPointer("/a/5", 3)
+	assert.ErrorIs(err, dynamic.ErrPointerNotFound)
+
+	_, err = doc.SetPointer("/c/d", 3)
+	assert.ErrorIs(err, dynamic.ErrPointerNotFound)
+
+	res, err = doc.SetPointer("", true)
+	assert.NoError(err)
+	assert.Equal(`true`, string(res))
+}
+
+func TestJSONDeletePointer(t *testing.T) {
+	assert := require.New(t)
+	doc := dynamic.JSON(`{"a": [1, 2, 3], "b": {"c": 1}, "d": 4}`)
+
+	res, err := doc.DeletePointer("/a/1")
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 3], "b": {"c": 1}, "d": 4}`, string(res))
+
+	res, err = doc.DeletePointer("/a/2")
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 2], "b": {"c": 1}, "d": 4}`, string(res))
+
+	res, err = doc.DeletePointer("/b/c")
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 2, 3], "b": {}, "d": 4}`, string(res))
+
+	res, err = doc.DeletePointer("/d")
+	assert.NoError(err)
+	assert.Equal(`{"a": [1, 2, 3], "b": {"c": 1}}`, string(res))
+
+	_, err = doc.DeletePointer("/e")
+	assert.ErrorIs(err, dynamic.ErrPointerNotFound)
+}
+
+func TestMapPointer(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{
+		"a": []interface{}{map[string]interface{}{"b": "c"}},
+		"j": dynamic.JSON(`{"k": [1, 2]}`),
+	}
+	v, err := m.Pointer("/a/0/b")
+	assert.NoError(err)
+	assert.Equal("c", v)
+
+	v, err = m.Pointer("/j/k/1")
+	assert.NoError(err)
+	assert.Equal(dynamic.JSON("2"), v)
+
+	_, err = m.Pointer("/j/k/2")
+	var perr *dynamic.PointerError
+	assert.ErrorAs(err, &perr)
+	assert.Equal("/j/k/2", perr.Pointer)
+
+	assert.NoError(m.SetPointer("/a/-", "d"))
+	assert.Equal("d", m["a"].([]interface{})[1])
+	assert.NoError(m.SetPointer("/a/0/e", 1))
+	assert.Equal(1, m["a"].([]interface{})[0].(map[string]interface{})["e"])
+	assert.NoError(m.SetPointer("/j/k/0", 3))
+	assert.Equal(`{"k": [3, 2]}`, string(m["j"].(dynamic.JSON)))
+	assert.ErrorIs(m.SetPointer("/x/y", 1), dynamic.ErrPointerNotFound)
+
+	assert.NoError(m.DeletePointer("/a/0"))
+	assert.Equal([]interface{}{"d"}, m["a"])
+	assert.NoError(m.DeletePointer("/j"))
+	_, ok := m["j"]
+	assert.False(ok)
+	assert.ErrorIs(m.DeletePointer("/j"), dynamic.ErrPointerNotFound)
+}
@@ -0,0 +1,155 @@
+package dynamic
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// appendQuote appends s to dst as a json string. Only the characters which
+// must be escaped are escaped: quotation marks, reverse solidus and control
+// characters. Invalid UTF-8 is replaced with U+FFFD.
+func appendQuote(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "�"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// unquote decodes the json string data, including its surrounding quotes.
+// Escape sequences, including UTF-16 surrogate pairs, are decoded. Lone
+// surrogates are replaced with U+FFFD.
+//
+// data is expected to have been scanned; only minimal checks are performed.
+func unquote(data []byte) (string, error) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return "", newScanner(data).errorAt(0, "invalid string")
+	}
+	data = data[1 : len(data)-1]
+	escaped := false
+	for _, c := range data {
+		if c == '\\' {
+			escaped = true
+			break
+		}
+	}
+	if !escaped {
+		return string(data), nil
+	}
+	buf := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c != '\\' {
+			buf = append(buf, c)
+			i++
+			continue
+		}
+		if i+1 >= len(data) {
+			return "", newScanner(data).errorAt(i, "invalid escape in string")
+		}
+		switch data[i+1] {
+		case '"', '\\', '/':
+			buf = append(buf, data[i+1])
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, ok := decodeHex4(data[i+2:])
+			if !ok {
+				return "", newScanner(data).errorAt(i, "invalid unicode escape in string")
+			}
+			i += 6
+			if utf16.IsSurrogate(r) {
+				if len(data) >= i+6 && data[i] == '\\' && data[i+1] == 'u' {
+					if r2, ok := decodeHex4(data[i+2:]); ok {
+						if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+							buf = appendRune(buf, dec)
+							i += 6
+							continue
+						}
+					}
+				}
+				r = utf8.RuneError
+			}
+			buf = appendRune(buf, r)
+			continue
+		default:
+			return "", newScanner(data).errorAt(i, "invalid escape in string")
+		}
+		i += 2
+	}
+	return string(buf), nil
+}
+
+func decodeHex4(data []byte) (rune, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range data[:4] {
+		switch {
+		case isDigit(c):
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(c)
+	}
+	return r, true
+}
+
+func appendRune(dst []byte, r rune) []byte {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	return append(dst, b[:n]...)
+}
@@ -0,0 +1,343 @@
+package dynamic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// RedactAction is the action a Redactor takes upon a matching value
+type RedactAction uint8
+
+const (
+	// RedactMask replaces the value with Redactor.Mask
+	RedactMask RedactAction = iota
+	// RedactHash replaces the value with the hex encoded SHA-256 of its
+	// canonical json encoding (RFC 8785), or the HMAC-SHA256 if
+	// Redactor.HashKey is set, so that values can be correlated without
+	// being revealed
+	RedactHash
+	// RedactRemove removes the member or element
+	RedactRemove
+)
+
+// DefaultMask is used by a Redactor without a Mask
+const DefaultMask = "[REDACTED]"
+
+// RedactRule selects values to redact, either by Path or by Key.
+type RedactRule struct {
+	// Path is a JSON Pointer (RFC 6901) to a value. A reference token of "*"
+	// matches any key or index.
+	Path string
+	// Key matches members of objects, at any depth, by key
+	Key *regexp.Regexp
+	// Action is taken upon matching values
+	Action RedactAction
+}
+
+// Redactor rewrites values to strip sensitive data. The first rule, in order,
+// to match a member or element is applied; the root value is never redacted.
+// The structure of the value is otherwise left intact.
+type Redactor struct {
+	Rules []RedactRule
+	// Mask replaces values redacted with RedactMask. DefaultMask is used if
+	// Mask is empty.
+	Mask string
+	// HashKey, if set, is the key of the HMAC used by RedactHash
+	HashKey []byte
+}
+
+type redactRule struct {
+	tokens []string
+	key    *regexp.Regexp
+	action RedactAction
+}
+
+func (r *Redactor) compile() ([]redactRule, error) {
+	rules := make([]redactRule, len(r.Rules))
+	for i, rule := range r.Rules {
+		rules[i] = redactRule{key: rule.Key, action: rule.Action}
+		if rule.Key != nil {
+			continue
+		}
+		tokens, err := ParsePointer(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		rules[i].tokens = tokens
+	}
+	return rules, nil
+}
+
+// match returns the rule which applies to the value at path. isKey reports
+// whether the last token of path is the key of an object member.
+func match(rules []redactRule, path []string, isKey bool) (redactRule, bool) {
+	for _, rule := range rules {
+		if rule.key != nil {
+			if isKey && rule.key.MatchString(path[len(path)-1]) {
+				return rule, true
+			}
+			continue
+		}
+		if len(rule.tokens) != len(path) {
+			continue
+		}
+		matched := true
+		for i, t := range rule.tokens {
+			if t != "*" && t != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule, true
+		}
+	}
+	return redactRule{}, false
+}
+
+// replacement returns the json which replaces v per action
+func (r *Redactor) replacement(action RedactAction, v JSON) (JSON, error) {
+	if action == RedactMask {
+		mask := r.Mask
+		if mask == "" {
+			mask = DefaultMask
+		}
+		return appendQuote(nil, mask), nil
+	}
+	c, err := v.Canonicalize()
+	if err != nil {
+		return nil, err
+	}
+	var sum []byte
+	if r.HashKey != nil {
+		h := hmac.New(sha256.New, r.HashKey)
+		h.Write(c)
+		sum = h.Sum(nil)
+	} else {
+		s := sha256.Sum256(c)
+		sum = s[:]
+	}
+	return appendQuote(nil, hex.EncodeToString(sum)), nil
+}
+
+// RedactJSON returns a copy of d with matching values redacted. Objects and
+// arrays containing redacted values are compacted; the formatting of d is
+// otherwise preserved.
+func (r *Redactor) RedactJSON(d JSON) (JSON, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	rules, err := r.compile()
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := r.redactJSON(rules, d.trimSpace(), nil)
+	return res, err
+}
+
+// redactJSON redacts the members of d, returning the result and whether
+// anything was redacted
+func (r *Redactor) redactJSON(rules []redactRule, d JSON, path []string) (JSON, bool, error) {
+	c, err := scanContainer(d, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.kind != KindObject && c.kind != KindArray {
+		return d, false, nil
+	}
+	changed := false
+	buf := []byte{'{'}
+	if c.kind == KindArray {
+		buf[0] = '['
+	}
+	n := 0
+	for i, m := range c.members {
+		v := d[m.value.start:m.value.end]
+		p := childPath(path, m.key)
+		if c.kind == KindArray {
+			p = childPath(path, strconv.Itoa(i))
+		}
+		if rule, ok := match(rules, p, c.kind == KindObject); ok {
+			changed = true
+			if rule.action == RedactRemove {
+				continue
+			}
+			if v, err = r.replacement(rule.action, v); err != nil {
+				return nil, false, err
+			}
+		} else {
+			var ok bool
+			if v, ok, err = r.redactJSON(rules, v, p); err != nil {
+				return nil, false, err
+			}
+			changed = changed || ok
+		}
+		if n > 0 {
+			buf = append(buf, ',')
+		}
+		if c.kind == KindObject {
+			buf = appendQuote(buf, m.key)
+			buf = append(buf, ':')
+		}
+		buf = append(buf, v...)
+		n++
+	}
+	if !changed {
+		return d, false, nil
+	}
+	if c.kind == KindArray {
+		return append(buf, ']'), true, nil
+	}
+	return append(buf, '}'), true, nil
+}
+
+// RedactMap returns a copy of m with matching values redacted; m is not
+// modified. Nested Map, map[string]interface{}, JSONObject, []interface{} and
+// JSON values are traversed. Other structs, maps, slices and arrays are
+// encoded as JSON and, if anything within them is redacted, replaced by the
+// redacted JSON.
+func (r *Redactor) RedactMap(m Map) (Map, error) {
+	rules, err := r.compile()
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.redactValue(rules, m, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.(Map), nil
+}
+
+func (r *Redactor) redactValue(rules []redactRule, v interface{}, path []string) (interface{}, error) {
+	if raw, ok := asRawJSON(v); ok && len(raw) > 0 {
+		if err := raw.Validate(); err != nil {
+			return nil, err
+		}
+		res, _, err := r.redactJSON(rules, raw.trimSpace(), path)
+		return res, err
+	}
+	switch t := v.(type) {
+	case Map:
+		res, err := r.redactMembers(rules, t, path)
+		return Map(res), err
+	case map[string]interface{}:
+		return r.redactMembers(rules, t, path)
+	case JSONObject:
+		res := make(JSONObject, len(t))
+		for k, v := range t {
+			p := childPath(path, k)
+			nv, keep, err := r.redactMember(rules, v, p, true)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				res[k] = nv.(JSON)
+			}
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, 0, len(t))
+		for i, v := range t {
+			nv, keep, err := r.redactMember(rules, v, childPath(path, strconv.Itoa(i)), false)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				res = append(res, nv)
+			}
+		}
+		return res, nil
+	}
+	if !isComposite(v) {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	res, changed, err := r.redactJSON(rules, data, path)
+	if err != nil || !changed {
+		return v, err
+	}
+	return res, nil
+}
+
+// isComposite reports whether v is, or points to, a struct, map, slice or
+// array
+func isComposite(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func (r *Redactor) redactMembers(rules []redactRule, obj map[string]interface{}, path []string) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		nv, keep, err := r.redactMember(rules, v, childPath(path, k), true)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			res[k] = nv
+		}
+	}
+	return res, nil
+}
+
+// redactMember redacts the member or element v found at path, returning the
+// result and whether it should be kept
+func (r *Redactor) redactMember(rules []redactRule, v interface{}, path []string, isKey bool) (interface{}, bool, error) {
+	rule, ok := match(rules, path, isKey)
+	if !ok {
+		res, err := r.redactValue(rules, v, path)
+		return res, true, err
+	}
+	if rule.action == RedactRemove {
+		return nil, false, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false, err
+	}
+	res, err := r.replacement(rule.action, data)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, ok := v.(JSON); ok {
+		return res, true, nil
+	}
+	s, err := res.String()
+	return s, true, err
+}
+
+// Wrap returns a json.Marshaler which encodes v redacted by r
+func (r *Redactor) Wrap(v interface{}) json.Marshaler {
+	return redacted{redactor: r, value: v}
+}
+
+type redacted struct {
+	redactor *Redactor
+	value    interface{}
+}
+
+func (rv redacted) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(rv.value)
+	if err != nil {
+		return nil, err
+	}
+	return rv.redactor.RedactJSON(data)
+}
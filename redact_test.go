@@ -0,0 +1,116 @@
+package dynamic_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+var testRedactor = &dynamic.Redactor{
+	Rules: []dynamic.RedactRule{
+		{Path: "/user/ssn", Action: dynamic.RedactRemove},
+		{Path: "/cards/*/number", Action: dynamic.RedactMask},
+		{Key: regexp.MustCompile(`(?i)^e-?mail$`), Action: dynamic.RedactHash},
+		{Key: regexp.MustCompile(`(?i)password`), Action: dynamic.RedactMask},
+	},
+}
+
+func TestRedactorRedactJSON(t *testing.T) {
+	assert := require.New(t)
+	data := dynamic.JSON(`{
+		"user": {"name": "ann", "ssn": "123-45-6789", "Email": "ann@example.com"},
+		"cards": [{"number": "4111", "exp": "01/30"}, {"number": "5500"}],
+		"query": {"match": {"password": "hunter2"}},
+		"untouched": [1, 2]
+	}`)
+	res, err := testRedactor.RedactJSON(data)
+	assert.NoError(err)
+
+	var m map[string]interface{}
+	assert.NoError(json.Unmarshal(res, &m))
+	user := m["user"].(map[string]interface{})
+	assert.NotContains(user, "ssn")
+	assert.Len(user["Email"], 64)
+	assert.NotEqual("ann@example.com", user["Email"])
+	assert.Contains(string(res), `"cards":[{"number":"[REDACTED]","exp":"01/30"},{"number":"[REDACTED]"}]`)
+	assert.Contains(string(res), `"query":{"match":{"password":"[REDACTED]"}}`)
+	assert.Contains(string(res), `"untouched":[1, 2]`)
+
+	same, err := testRedactor.RedactJSON(dynamic.JSON(`{"user":{"email":"ann@example.com"}}`))
+	assert.NoError(err)
+	assert.Equal(`{"user":{"email":"`+user["Email"].(string)+`"}}`, string(same))
+
+	keyed := &dynamic.Redactor{Rules: testRedactor.Rules, HashKey: []byte("secret")}
+	other, err := keyed.RedactJSON(dynamic.JSON(`{"email":"ann@example.com"}`))
+	assert.NoError(err)
+	assert.NotContains(string(other), user["Email"].(string))
+
+	unchanged := dynamic.JSON(`{ "a" : 1 }`)
+	res, err = testRedactor.RedactJSON(unchanged)
+	assert.NoError(err)
+	assert.Equal(string(unchanged), string(res))
+
+	_, err = (&dynamic.Redactor{Rules: []dynamic.RedactRule{{Path: "a"}}}).RedactJSON(unchanged)
+	assert.ErrorIs(err, dynamic.ErrInvalidPointer)
+}
+
+func TestRedactorRedactMap(t *testing.T) {
+	assert := require.New(t)
+	m := dynamic.Map{
+		"user": map[string]interface{}{"name": "ann", "ssn": "123", "email": "ann@example.com"},
+		"cards": []interface{}{
+			dynamic.Map{"number": "4111"},
+		},
+		"raw": dynamic.JSON(`{"password": "x", "keep": true}`),
+	}
+	res, err := (&dynamic.Redactor{Rules: testRedactor.Rules, Mask: "***"}).RedactMap(m)
+	assert.NoError(err)
+
+	user := res["user"].(map[string]interface{})
+	assert.NotContains(user, "ssn")
+	assert.Equal("ann", user["name"])
+	assert.NotEqual("ann@example.com", user["email"])
+	assert.Equal(dynamic.Map{"number": "***"}, res["cards"].([]interface{})[0])
+	assert.Equal(`{"password":"***","keep":true}`, string(res["raw"].(dynamic.JSON)))
+
+	// m is not modified
+	assert.Equal("123", m["user"].(map[string]interface{})["ssn"])
+	assert.Equal("4111", m["cards"].([]interface{})[0].(dynamic.Map)["number"])
+
+	type account struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	m = dynamic.Map{
+		"u":      account{Name: "ann", Password: "x"},
+		"ptr":    &account{Name: "bob", Password: "y"},
+		"list":   []dynamic.Map{{"password": "z"}},
+		"typed":  map[string]string{"password": "w"},
+		"clean":  encodeChild{Name: "cat"},
+		"scalar": 1,
+	}
+	res, err = (&dynamic.Redactor{Rules: []dynamic.RedactRule{{Key: regexp.MustCompile(`^password$`)}}}).RedactMap(m)
+	assert.NoError(err)
+	assert.Equal(`{"name":"ann","password":"[REDACTED]"}`, string(res["u"].(dynamic.JSON)))
+	assert.Equal(`{"name":"bob","password":"[REDACTED]"}`, string(res["ptr"].(dynamic.JSON)))
+	assert.Equal(`[{"password":"[REDACTED]"}]`, string(res["list"].(dynamic.JSON)))
+	assert.Equal(`{"password":"[REDACTED]"}`, string(res["typed"].(dynamic.JSON)))
+	assert.Equal(m["clean"], res["clean"])
+	assert.Equal(1, res["scalar"])
+}
+
+func TestRedactorWrap(t *testing.T) {
+	assert := require.New(t)
+	type request struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"request": testRedactor.Wrap(request{User: "ann", Password: "hunter2"}),
+	})
+	assert.NoError(err)
+	assert.Equal(`{"request":{"user":"ann","password":"[REDACTED]"}}`, string(data))
+}
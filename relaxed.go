@@ -0,0 +1,491 @@
+package dynamic
+
+import (
+	"math/big"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ParseRelaxed parses data as JSON5 (https://spec.json5.org), which is a
+// superset of JSONC, and returns the equivalent compact, strict json.
+//
+// In addition to json, the following are accepted:
+//   - single line (//) and multi-line (/* */) comments
+//   - trailing commas in objects and arrays
+//   - single quoted strings and the additional escapes of JSON5
+//   - object keys which are ECMAScript identifiers
+//   - hexadecimal numbers, leading or trailing decimal points and a leading +
+//
+// Infinity and NaN have no json equivalent and result in an error; see
+// RelaxedOptions to convert them to null instead.
+//
+// A *SyntaxError with the line and column of the offending token is returned
+// if data is malformed.
+func ParseRelaxed(data []byte) (JSON, error) {
+	return RelaxedOptions{}.Parse(data)
+}
+
+// RelaxedOptions configures the parsing of JSON5
+type RelaxedOptions struct {
+	// NonFiniteAsNull converts Infinity, -Infinity and NaN to null rather
+	// than returning an error. The conversion is lossy.
+	NonFiniteAsNull bool
+}
+
+// Parse parses data as JSON5 per opts. See ParseRelaxed for details.
+func (opts RelaxedOptions) Parse(data []byte) (JSON, error) {
+	p := &relaxedParser{scanner: newScanner(data), opts: opts}
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	if err := p.value(); err != nil {
+		return nil, err
+	}
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.unexpected()
+	}
+	return p.buf, nil
+}
+
+type relaxedParser struct {
+	*scanner
+	opts RelaxedOptions
+	buf  JSON
+}
+
+// nonFinite writes null in place of Infinity or NaN, which begins at start,
+// if permitted by the options of p
+func (p *relaxedParser) nonFinite(start int, ident string) error {
+	if !p.opts.NonFiniteAsNull {
+		return p.errorAt(start, ident+" has no json equivalent")
+	}
+	p.buf = append(p.buf, "null"...)
+	return nil
+}
+
+// skip advances past whitespace and comments
+func (p *relaxedParser) skip() error {
+	for !p.eof() {
+		c := p.data[p.pos]
+		switch {
+		case isSpace(c) || c == '\v' || c == '\f':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for !p.eof() && !p.atLineTerminator() {
+				p.pos++
+			}
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '*':
+			start := p.pos
+			p.pos += 2
+			for {
+				if p.pos+1 >= len(p.data) {
+					return p.errorAt(start, "unterminated comment")
+				}
+				if p.data[p.pos] == '*' && p.data[p.pos+1] == '/' {
+					p.pos += 2
+					break
+				}
+				p.pos++
+			}
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			if r != '\ufeff' && r != '\u2028' && r != '\u2029' && !unicode.Is(unicode.Zs, r) {
+				return nil
+			}
+			p.pos += size
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// atLineTerminator reports whether the input at the current position is a
+// line terminator
+func (p *relaxedParser) atLineTerminator() bool {
+	switch p.data[p.pos] {
+	case '\n', '\r':
+		return true
+	case 0xE2:
+		r, _ := utf8.DecodeRune(p.data[p.pos:])
+		return r == '\u2028' || r == '\u2029'
+	}
+	return false
+}
+
+func (p *relaxedParser) value() error {
+	switch c := p.peek(); {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || c == '\'':
+		s, err := p.string()
+		if err != nil {
+			return err
+		}
+		p.buf = appendQuote(p.buf, s)
+		return nil
+	case c == '-' || c == '+' || c == '.' || isDigit(c):
+		return p.number()
+	}
+	start := p.pos
+	ident, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	switch ident {
+	case "true", "false", "null":
+		p.buf = append(p.buf, ident...)
+	case "Infinity", "NaN":
+		return p.nonFinite(start, ident)
+	default:
+		return p.errorAt(start, "unexpected token")
+	}
+	return nil
+}
+
+func (p *relaxedParser) object() error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer p.leave()
+	p.pos++
+	p.buf = append(p.buf, '{')
+	for n := 0; ; n++ {
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.peek() == '}' {
+			p.pos++
+			p.buf = append(p.buf, '}')
+			return nil
+		}
+		if n > 0 {
+			p.buf = append(p.buf, ',')
+		}
+		var key string
+		var err error
+		if c := p.peek(); c == '"' || c == '\'' {
+			key, err = p.string()
+		} else {
+			key, err = p.identifier()
+		}
+		if err != nil {
+			return err
+		}
+		p.buf = appendQuote(p.buf, key)
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.peek() != ':' {
+			return p.unexpected()
+		}
+		p.pos++
+		p.buf = append(p.buf, ':')
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if err := p.value(); err != nil {
+			return err
+		}
+		if err := p.skip(); err != nil {
+			return err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return p.unexpected()
+		}
+	}
+}
+
+func (p *relaxedParser) array() error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer p.leave()
+	p.pos++
+	p.buf = append(p.buf, '[')
+	for n := 0; ; n++ {
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.peek() == ']' {
+			p.pos++
+			p.buf = append(p.buf, ']')
+			return nil
+		}
+		if n > 0 {
+			p.buf = append(p.buf, ',')
+		}
+		if err := p.value(); err != nil {
+			return err
+		}
+		if err := p.skip(); err != nil {
+			return err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return p.unexpected()
+		}
+	}
+}
+
+// number parses a JSON5 number and writes it as a json number
+func (p *relaxedParser) number() error {
+	start := p.pos
+	neg := false
+	switch p.peek() {
+	case '-':
+		neg = true
+		p.pos++
+	case '+':
+		p.pos++
+	}
+	if c := p.peek(); c == 'I' || c == 'N' {
+		ident, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		if ident != "Infinity" && ident != "NaN" {
+			return p.errorAt(start, "invalid number")
+		}
+		return p.nonFinite(start, ident)
+	}
+	if neg {
+		p.buf = append(p.buf, '-')
+	}
+	if p.peek() == '0' && p.pos+1 < len(p.data) && (p.data[p.pos+1] == 'x' || p.data[p.pos+1] == 'X') {
+		p.pos += 2
+		digits := p.pos
+		for !p.eof() && isHex(p.data[p.pos]) {
+			p.pos++
+		}
+		n, ok := new(big.Int).SetString(string(p.data[digits:p.pos]), 16)
+		if !ok {
+			return p.errorAt(start, "invalid number")
+		}
+		p.buf = n.Append(p.buf, 10)
+		return nil
+	}
+
+	intStart := p.pos
+	for !p.eof() && isDigit(p.data[p.pos]) {
+		p.pos++
+	}
+	intPart := p.data[intStart:p.pos]
+	if len(intPart) > 1 && intPart[0] == '0' {
+		return p.errorAt(start, "invalid number")
+	}
+	var fracPart []byte
+	if p.peek() == '.' {
+		p.pos++
+		fracStart := p.pos
+		for !p.eof() && isDigit(p.data[p.pos]) {
+			p.pos++
+		}
+		fracPart = p.data[fracStart:p.pos]
+	}
+	if len(intPart) == 0 && len(fracPart) == 0 {
+		return p.errorAt(start, "invalid number")
+	}
+	if len(intPart) == 0 {
+		p.buf = append(p.buf, '0')
+	}
+	p.buf = append(p.buf, intPart...)
+	if len(fracPart) > 0 {
+		p.buf = append(p.buf, '.')
+		p.buf = append(p.buf, fracPart...)
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		expStart := p.pos
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		digits := p.pos
+		for !p.eof() && isDigit(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == digits {
+			return p.errorAt(start, "invalid number")
+		}
+		p.buf = append(p.buf, p.data[expStart:p.pos]...)
+	}
+	return nil
+}
+
+// string parses a single or double quoted JSON5 string, returning its value
+func (p *relaxedParser) string() (string, error) {
+	start := p.pos
+	quote := p.data[p.pos]
+	p.pos++
+	var s []byte
+	for {
+		if p.eof() || p.data[p.pos] == '\n' || p.data[p.pos] == '\r' {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return string(s), nil
+		case c == '\\':
+			var err error
+			if s, err = p.escape(s); err != nil {
+				return "", err
+			}
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return "", p.errorAt(p.pos, "invalid UTF-8 in string")
+			}
+			s = append(s, p.data[p.pos:p.pos+size]...)
+			p.pos += size
+		default:
+			s = append(s, c)
+			p.pos++
+		}
+	}
+}
+
+// escape decodes the escape sequence at the current position, appending it
+// to s
+func (p *relaxedParser) escape(s []byte) ([]byte, error) {
+	start := p.pos
+	p.pos++
+	if p.eof() {
+		return nil, p.errorAt(start, "unterminated string")
+	}
+	c := p.data[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		return append(s, '\b'), nil
+	case 'f':
+		return append(s, '\f'), nil
+	case 'n':
+		return append(s, '\n'), nil
+	case 'r':
+		return append(s, '\r'), nil
+	case 't':
+		return append(s, '\t'), nil
+	case 'v':
+		return append(s, '\v'), nil
+	case '0':
+		if isDigit(p.peek()) {
+			return nil, p.errorAt(start, "invalid escape in string")
+		}
+		return append(s, 0), nil
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return nil, p.errorAt(start, "invalid escape in string")
+	case '\r':
+		// line continuation
+		if p.peek() == '\n' {
+			p.pos++
+		}
+		return s, nil
+	case '\n':
+		// line continuation
+		return s, nil
+	case 'x':
+		if p.pos+2 > len(p.data) || !isHex(p.data[p.pos]) || !isHex(p.data[p.pos+1]) {
+			return nil, p.errorAt(start, "invalid hex escape in string")
+		}
+		r, _ := decodeHex4(append([]byte("00"), p.data[p.pos:p.pos+2]...))
+		p.pos += 2
+		return appendRune(s, r), nil
+	case 'u':
+		r, err := p.unicodeEscape(start)
+		if err != nil {
+			return nil, err
+		}
+		return appendRune(s, r), nil
+	}
+	p.pos--
+	r, size := utf8.DecodeRune(p.data[p.pos:])
+	p.pos += size
+	if r == '\u2028' || r == '\u2029' {
+		// line continuation
+		return s, nil
+	}
+	return appendRune(s, r), nil
+}
+
+// unicodeEscape decodes a \u escape, the "\u" of which has been consumed.
+// UTF-16 surrogate pairs are combined; lone surrogates become U+FFFD.
+func (p *relaxedParser) unicodeEscape(start int) (rune, error) {
+	r, ok := decodeHex4(p.data[p.pos:])
+	if !ok {
+		return 0, p.errorAt(start, "invalid unicode escape")
+	}
+	p.pos += 4
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+	if p.pos+6 <= len(p.data) && p.data[p.pos] == '\\' && p.data[p.pos+1] == 'u' {
+		if r2, ok := decodeHex4(p.data[p.pos+2:]); ok {
+			if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+				p.pos += 6
+				return dec, nil
+			}
+		}
+	}
+	return utf8.RuneError, nil
+}
+
+// identifier parses an ECMAScript IdentifierName, returning its value
+func (p *relaxedParser) identifier() (string, error) {
+	start := p.pos
+	var s []byte
+	for !p.eof() {
+		pos := p.pos
+		var r rune
+		if p.data[p.pos] == '\\' {
+			if p.pos+1 >= len(p.data) || p.data[p.pos+1] != 'u' {
+				return "", p.errorAt(pos, "invalid escape in identifier")
+			}
+			p.pos += 2
+			var err error
+			if r, err = p.unicodeEscape(pos); err != nil {
+				return "", err
+			}
+			if !isIdentifierRune(r, len(s) == 0) {
+				return "", p.errorAt(pos, "invalid escape in identifier")
+			}
+		} else {
+			var size int
+			r, size = utf8.DecodeRune(p.data[p.pos:])
+			if !isIdentifierRune(r, len(s) == 0) {
+				break
+			}
+			p.pos += size
+		}
+		s = appendRune(s, r)
+	}
+	if len(s) == 0 {
+		p.pos = start
+		return "", p.unexpected()
+	}
+	return string(s), nil
+}
+
+func isIdentifierRune(r rune, first bool) bool {
+	switch {
+	case r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r):
+		return true
+	case first:
+		return false
+	}
+	return r == '\u200c' || r == '\u200d' || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
@@ -0,0 +1,75 @@
+package dynamic_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRelaxed(t *testing.T) {
+	assert := require.New(t)
+	data := `// config
+{
+	/* multi
+	   line */
+	name: 'app "one"',
+	$version: +2,
+	"ratio": .5,
+	count: 5.,
+	mask: 0xFF,
+	neg: -0X10,
+	exp: 1e3,
+	big: 0x10000000000000000,
+	limits: [Infinity, -Infinity, NaN,],
+	escapes: 'tab\tquote\'\x41é😀\
+continued',
+	nested: {a: [1, 2, {}, [],], b: null, c: true,},
+}
+`
+	_, err := dynamic.ParseRelaxed([]byte(data))
+	var serr *dynamic.SyntaxError
+	assert.True(errors.As(err, &serr))
+	assert.Equal(13, serr.Line)
+	assert.Equal(11, serr.Column)
+
+	res, err := dynamic.RelaxedOptions{NonFiniteAsNull: true}.Parse([]byte(data))
+	assert.NoError(err)
+	assert.True(res.Valid())
+	assert.Equal(`{"name":"app \"one\"","$version":2,"ratio":0.5,"count":5,"mask":255,"neg":-16,"exp":1e3,"big":18446744073709551616,"limits":[null,null,null],"escapes":"tab\tquote'Aé😀continued","nested":{"a":[1,2,{},[]],"b":null,"c":true}}`, string(res))
+
+	res, err = dynamic.ParseRelaxed([]byte(`  "plain" // trailing`))
+	assert.NoError(err)
+	assert.Equal(`"plain"`, string(res))
+}
+
+func TestParseRelaxedErrors(t *testing.T) {
+	assert := require.New(t)
+	tests := []struct {
+		data   string
+		line   int
+		column int
+	}{
+		{"{\n  a: 1,\n  b 2\n}", 3, 5},
+		{"[1, 2,, 3]", 1, 7},
+		{"{a: 'unterminated\n}", 1, 5},
+		{"/* never closed", 1, 1},
+		{"[01]", 1, 2},
+		{"{a: undefined}", 1, 5},
+		{"[1] 2", 1, 5},
+		{"{'a' 1}", 1, 6},
+		{"'\\1'", 1, 2},
+		{"", 1, 1},
+		{strings.Repeat("[", 5000000), 1, dynamic.MaxNestingDepth + 1},
+	}
+	for _, test := range tests {
+		_, err := dynamic.ParseRelaxed([]byte(test.data))
+		var serr *dynamic.SyntaxError
+		assert.True(errors.As(err, &serr), test.data)
+		assert.ErrorIs(err, dynamic.ErrMalformedJSON)
+		assert.Equal(test.line, serr.Line, test.data)
+		assert.Equal(test.column, serr.Column, test.data)
+	}
+}
@@ -0,0 +1,420 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrMalformedJSON is wrapped by every SyntaxError
+var ErrMalformedJSON = errors.New("dynamic: malformed json")
+
+// SyntaxError is returned when data is not valid json as described by RFC
+// 8259. It contains the position and the offending token of the first error
+// encountered.
+type SyntaxError struct {
+	// Offset is the byte offset, starting at 0, of the offending token
+	Offset int
+	// Line is the line number, starting at 1, of the offending token
+	Line int
+	// Column is the column, in bytes and starting at 1, of the offending token
+	Column int
+	// Token is the offending token. It is empty if the end of input was
+	// reached unexpectedly.
+	Token string
+	msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("dynamic: %s at line %d, column %d (offset %d)", e.msg, e.Line, e.Column, e.Offset)
+	}
+	return fmt.Sprintf("dynamic: %s %q at line %d, column %d (offset %d)", e.msg, e.Token, e.Line, e.Column, e.Offset)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrMalformedJSON
+}
+
+// MaxNestingDepth is the deepest nesting of objects and arrays which is
+// accepted. Deeper json is reported as a SyntaxError.
+const MaxNestingDepth = 10000
+
+// scanner is a recursive descent scanner over raw json. It does not decode
+// values, it only verifies them and reports where they start and end.
+type scanner struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func newScanner(data []byte) *scanner {
+	return &scanner{data: data}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.data)
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) && isSpace(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+// peek returns the byte at the current position or 0 if there is no more input
+func (s *scanner) peek() byte {
+	if s.eof() {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+// errorAt returns a SyntaxError for the token found at offset
+func (s *scanner) errorAt(offset int, msg string) *SyntaxError {
+	line, col := 1, 1
+	for i := 0; i < offset && i < len(s.data); i++ {
+		if s.data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &SyntaxError{
+		Offset: offset,
+		Line:   line,
+		Column: col,
+		Token:  tokenAt(s.data, offset),
+		msg:    msg,
+	}
+}
+
+func (s *scanner) unexpected() *SyntaxError {
+	if s.eof() {
+		return s.errorAt(s.pos, "unexpected end of input")
+	}
+	return s.errorAt(s.pos, "unexpected token")
+}
+
+// tokenAt returns the token found at offset. Runs of characters which could
+// make up a literal or a number are returned together, anything else is
+// returned as a single rune.
+func tokenAt(data []byte, offset int) string {
+	if offset >= len(data) {
+		return ""
+	}
+	end := offset
+	for end < len(data) && isLiteralByte(data[end]) {
+		end++
+	}
+	if end > offset {
+		return string(data[offset:end])
+	}
+	_, size := utf8.DecodeRune(data[offset:])
+	return string(data[offset : offset+size])
+}
+
+func isLiteralByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '-' || c == '+' || c == '.'
+}
+
+// scanDocument scans the entirety of data as a single json value surrounded
+// by optional whitespace.
+func (s *scanner) scanDocument() error {
+	s.skipSpace()
+	if err := s.scanValue(); err != nil {
+		return err
+	}
+	return s.expectEOF()
+}
+
+// expectEOF reports an error if anything other than whitespace remains
+func (s *scanner) expectEOF() error {
+	s.skipSpace()
+	if !s.eof() {
+		return s.errorAt(s.pos, "unexpected token after top-level value")
+	}
+	return nil
+}
+
+// scanValue scans the value starting at the current position, leaving the
+// scanner positioned immediately after it.
+func (s *scanner) scanValue() error {
+	switch c := s.peek(); {
+	case c == '{':
+		return s.scanObject()
+	case c == '[':
+		return s.scanArray()
+	case c == '"':
+		return s.scanString()
+	case c == '-' || isDigit(c):
+		return s.scanNumber()
+	case c == 't':
+		return s.scanLiteral("true")
+	case c == 'f':
+		return s.scanLiteral("false")
+	case c == 'n':
+		return s.scanLiteral("null")
+	default:
+		return s.unexpected()
+	}
+}
+
+func (s *scanner) scanLiteral(lit string) error {
+	start := s.pos
+	for i := 0; i < len(lit); i++ {
+		if s.pos >= len(s.data) {
+			return s.errorAt(start, "unexpected end of input in literal")
+		}
+		if s.data[s.pos] != lit[i] {
+			return s.errorAt(start, "invalid literal")
+		}
+		s.pos++
+	}
+	if s.pos < len(s.data) && isLiteralByte(s.data[s.pos]) {
+		return s.errorAt(start, "invalid literal")
+	}
+	return nil
+}
+
+func (s *scanner) scanNumber() error {
+	start := s.pos
+	if s.peek() == '-' {
+		s.pos++
+	}
+	switch c := s.peek(); {
+	case c == '0':
+		s.pos++
+	case c >= '1' && c <= '9':
+		for isDigit(s.peek()) {
+			s.pos++
+		}
+	default:
+		return s.errorAt(start, "invalid number")
+	}
+	if s.peek() == '.' {
+		s.pos++
+		if !isDigit(s.peek()) {
+			return s.errorAt(start, "invalid number")
+		}
+		for isDigit(s.peek()) {
+			s.pos++
+		}
+	}
+	if c := s.peek(); c == 'e' || c == 'E' {
+		s.pos++
+		if c := s.peek(); c == '+' || c == '-' {
+			s.pos++
+		}
+		if !isDigit(s.peek()) {
+			return s.errorAt(start, "invalid number")
+		}
+		for isDigit(s.peek()) {
+			s.pos++
+		}
+	}
+	if s.pos < len(s.data) && isLiteralByte(s.data[s.pos]) {
+		return s.errorAt(start, "invalid number")
+	}
+	return nil
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func (s *scanner) scanString() error {
+	start := s.pos
+	s.pos++ // opening quote
+	for {
+		if s.eof() {
+			return s.errorAt(start, "unterminated string")
+		}
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return nil
+		case c == '\\':
+			s.pos++
+			switch s.peek() {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				s.pos++
+			case 'u':
+				s.pos++
+				for i := 0; i < 4; i++ {
+					if !isHex(s.peek()) {
+						return s.errorAt(s.pos, "invalid unicode escape in string")
+					}
+					s.pos++
+				}
+			case 0:
+				if s.eof() {
+					return s.errorAt(start, "unterminated string")
+				}
+				return s.errorAt(s.pos-1, "invalid escape in string")
+			default:
+				return s.errorAt(s.pos-1, "invalid escape in string")
+			}
+		case c < 0x20:
+			return s.errorAt(s.pos, "invalid control character in string")
+		case c < utf8.RuneSelf:
+			s.pos++
+		default:
+			r, size := utf8.DecodeRune(s.data[s.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return s.errorAt(s.pos, "invalid utf-8 in string")
+			}
+			s.pos += size
+		}
+	}
+}
+
+// span is the range of a value within the scanned data
+type span struct {
+	start int
+	end   int
+}
+
+// enter increments the nesting depth upon the start of an object or array.
+// leave must be called once it has been scanned.
+func (s *scanner) enter() error {
+	s.depth++
+	if s.depth > MaxNestingDepth {
+		return s.errorAt(s.pos, "exceeded max nesting depth")
+	}
+	return nil
+}
+
+func (s *scanner) leave() {
+	s.depth--
+}
+
+func (s *scanner) scanObject() error {
+	return s.scanMembers(nil)
+}
+
+func (s *scanner) scanArray() error {
+	return s.scanElements(nil)
+}
+
+// scanMembers scans the object starting at the current position, calling fn,
+// if not nil, with the span of each member's key and value. If fn returns
+// Done, scanning stops and nil is returned; the scanner is left positioned
+// after the member's value.
+func (s *scanner) scanMembers(fn func(key span, value span) error) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	s.pos++ // {
+	s.skipSpace()
+	if s.peek() == '}' {
+		s.pos++
+		return nil
+	}
+	for {
+		if s.peek() != '"' {
+			if s.eof() {
+				return s.unexpected()
+			}
+			return s.errorAt(s.pos, "expected string for object key")
+		}
+		key := span{start: s.pos}
+		if err := s.scanString(); err != nil {
+			return err
+		}
+		key.end = s.pos
+		s.skipSpace()
+		if s.peek() != ':' {
+			if s.eof() {
+				return s.unexpected()
+			}
+			return s.errorAt(s.pos, "expected ':' after object key")
+		}
+		s.pos++
+		s.skipSpace()
+		value := span{start: s.pos}
+		if err := s.scanValue(); err != nil {
+			return err
+		}
+		value.end = s.pos
+		if fn != nil {
+			if err := fn(key, value); err != nil {
+				if err == Done {
+					return nil
+				}
+				return err
+			}
+		}
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+			s.skipSpace()
+		case '}':
+			s.pos++
+			return nil
+		default:
+			if s.eof() {
+				return s.unexpected()
+			}
+			return s.errorAt(s.pos, "expected ',' or '}' after object value")
+		}
+	}
+}
+
+// scanElements scans the array starting at the current position, calling fn,
+// if not nil, with the index and span of each element. If fn returns Done,
+// scanning stops and nil is returned; the scanner is left positioned after
+// the element.
+func (s *scanner) scanElements(fn func(i int, value span) error) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	s.pos++ // [
+	s.skipSpace()
+	if s.peek() == ']' {
+		s.pos++
+		return nil
+	}
+	for i := 0; ; i++ {
+		value := span{start: s.pos}
+		if err := s.scanValue(); err != nil {
+			return err
+		}
+		value.end = s.pos
+		if fn != nil {
+			if err := fn(i, value); err != nil {
+				if err == Done {
+					return nil
+				}
+				return err
+			}
+		}
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+			s.skipSpace()
+		case ']':
+			s.pos++
+			return nil
+		default:
+			if s.eof() {
+				return s.unexpected()
+			}
+			return s.errorAt(s.pos, "expected ',' or ']' after array element")
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chanced/dynamic"
+)
+
+// compiler compiles a schema document. Schemas are cached by their location
+// so that references, including recursive ones, resolve to a single *Schema.
+type compiler struct {
+	root    interface{}
+	schemas map[string]*Schema
+}
+
+func (c *compiler) errorf(loc []string, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %q: %s", ErrInvalidSchema, dynamic.FormatPointer(loc...), fmt.Sprintf(format, args...))
+}
+
+func (c *compiler) compile(v interface{}, loc []string) (*Schema, error) {
+	key := dynamic.FormatPointer(loc...)
+	if s, ok := c.schemas[key]; ok {
+		return s, nil
+	}
+	s := &Schema{location: key}
+	c.schemas[key] = s
+
+	var obj map[string]interface{}
+	switch t := v.(type) {
+	case bool:
+		s.boolean = &t
+		return s, nil
+	case map[string]interface{}:
+		obj = t
+	default:
+		return nil, c.errorf(loc, "schema must be an object or a boolean")
+	}
+
+	var err error
+	if s.types, err = c.types(obj, loc); err != nil {
+		return nil, err
+	}
+	if e, ok := obj["enum"]; ok {
+		values, ok := e.([]interface{})
+		if !ok {
+			return nil, c.errorf(child(loc, "enum"), "must be an array")
+		}
+		for _, v := range values {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			s.enum = append(s.enum, data)
+		}
+	}
+	if v, ok := obj["const"]; ok {
+		if s.constant, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.properties, err = c.schemaMap(obj, loc, "properties"); err != nil {
+		return nil, err
+	}
+	if s.additionalProperties, err = c.schema(obj, loc, "additionalProperties"); err != nil {
+		return nil, err
+	}
+	if r, ok := obj["required"]; ok {
+		values, ok := r.([]interface{})
+		if !ok {
+			return nil, c.errorf(child(loc, "required"), "must be an array of strings")
+		}
+		for _, v := range values {
+			name, ok := v.(string)
+			if !ok {
+				return nil, c.errorf(child(loc, "required"), "must be an array of strings")
+			}
+			s.required = append(s.required, name)
+		}
+	}
+	if s.minProperties, err = c.count(obj, loc, "minProperties"); err != nil {
+		return nil, err
+	}
+	if s.maxProperties, err = c.count(obj, loc, "maxProperties"); err != nil {
+		return nil, err
+	}
+
+	if s.prefixItems, err = c.schemaList(obj, loc, "prefixItems"); err != nil {
+		return nil, err
+	}
+	if s.items, err = c.schema(obj, loc, "items"); err != nil {
+		return nil, err
+	}
+	if s.minItems, err = c.count(obj, loc, "minItems"); err != nil {
+		return nil, err
+	}
+	if s.maxItems, err = c.count(obj, loc, "maxItems"); err != nil {
+		return nil, err
+	}
+
+	if s.allOf, err = c.schemaList(obj, loc, "allOf"); err != nil {
+		return nil, err
+	}
+	if s.anyOf, err = c.schemaList(obj, loc, "anyOf"); err != nil {
+		return nil, err
+	}
+	if s.oneOf, err = c.schemaList(obj, loc, "oneOf"); err != nil {
+		return nil, err
+	}
+	if s.not, err = c.schema(obj, loc, "not"); err != nil {
+		return nil, err
+	}
+	if r, ok := obj["$ref"]; ok {
+		ref, ok := r.(string)
+		if !ok {
+			return nil, c.errorf(child(loc, "$ref"), "must be a string")
+		}
+		if s.ref, err = c.resolve(ref, child(loc, "$ref")); err != nil {
+			return nil, err
+		}
+	}
+
+	if p, ok := obj["pattern"]; ok {
+		pattern, ok := p.(string)
+		if !ok {
+			return nil, c.errorf(child(loc, "pattern"), "must be a string")
+		}
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, c.errorf(child(loc, "pattern"), "%v", err)
+		}
+	}
+	if s.minLength, err = c.count(obj, loc, "minLength"); err != nil {
+		return nil, err
+	}
+	if s.maxLength, err = c.count(obj, loc, "maxLength"); err != nil {
+		return nil, err
+	}
+	if f, ok := obj["format"]; ok {
+		if s.format, ok = f.(string); !ok {
+			return nil, c.errorf(child(loc, "format"), "must be a string")
+		}
+	}
+
+	if s.minimum, err = c.number(obj, loc, "minimum"); err != nil {
+		return nil, err
+	}
+	if s.maximum, err = c.number(obj, loc, "maximum"); err != nil {
+		return nil, err
+	}
+	if s.exclusiveMinimum, err = c.number(obj, loc, "exclusiveMinimum"); err != nil {
+		return nil, err
+	}
+	if s.exclusiveMaximum, err = c.number(obj, loc, "exclusiveMaximum"); err != nil {
+		return nil, err
+	}
+	if s.multipleOf, err = c.number(obj, loc, "multipleOf"); err != nil {
+		return nil, err
+	}
+	if s.multipleOf != nil && s.multipleOf.Sign() <= 0 {
+		return nil, c.errorf(child(loc, "multipleOf"), "must be greater than 0")
+	}
+	return s, nil
+}
+
+var typeNames = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"string":  true,
+	"integer": true,
+}
+
+func (c *compiler) types(obj map[string]interface{}, loc []string) ([]string, error) {
+	t, ok := obj["type"]
+	if !ok {
+		return nil, nil
+	}
+	var types []string
+	switch t := t.(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return nil, c.errorf(child(loc, "type"), "must be a string or an array of strings")
+			}
+			types = append(types, name)
+		}
+	default:
+		return nil, c.errorf(child(loc, "type"), "must be a string or an array of strings")
+	}
+	for _, name := range types {
+		if !typeNames[name] {
+			return nil, c.errorf(child(loc, "type"), "unknown type %q", name)
+		}
+	}
+	return types, nil
+}
+
+// schema compiles the subschema of keyword, if present
+func (c *compiler) schema(obj map[string]interface{}, loc []string, keyword string) (*Schema, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	return c.compile(v, child(loc, keyword))
+}
+
+// schemaList compiles the array of subschemas of keyword, if present
+func (c *compiler) schemaList(obj map[string]interface{}, loc []string, keyword string) ([]*Schema, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := v.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, c.errorf(child(loc, keyword), "must be a non-empty array of schemas")
+	}
+	res := make([]*Schema, len(values))
+	for i, v := range values {
+		var err error
+		if res[i], err = c.compile(v, child(loc, keyword, strconv.Itoa(i))); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// schemaMap compiles the object of subschemas of keyword, if present
+func (c *compiler) schemaMap(obj map[string]interface{}, loc []string, keyword string) (map[string]*Schema, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, c.errorf(child(loc, keyword), "must be an object of schemas")
+	}
+	res := make(map[string]*Schema, len(values))
+	for k, v := range values {
+		var err error
+		if res[k], err = c.compile(v, child(loc, keyword, k)); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// count returns the non-negative integer value of keyword, if present
+func (c *compiler) count(obj map[string]interface{}, loc []string, keyword string) (*int, error) {
+	n, err := c.number(obj, loc, keyword)
+	if n == nil || err != nil {
+		return nil, err
+	}
+	if !n.IsInt() || n.Sign() < 0 || !n.Num().IsInt64() || int64(int(n.Num().Int64())) != n.Num().Int64() {
+		return nil, c.errorf(child(loc, keyword), "must be a non-negative integer")
+	}
+	i := int(n.Num().Int64())
+	return &i, nil
+}
+
+// number returns the numeric value of keyword, if present
+func (c *compiler) number(obj map[string]interface{}, loc []string, keyword string) (*big.Rat, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, c.errorf(child(loc, keyword), "must be a number")
+	}
+	r, err := rat(n)
+	if err != nil {
+		return nil, c.errorf(child(loc, keyword), "%v", err)
+	}
+	return r, nil
+}
+
+// resolve compiles the schema referenced by ref, which must be a JSON Pointer
+// fragment
+func (c *compiler) resolve(ref string, loc []string) (*Schema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, c.errorf(loc, "unsupported reference %q; only references within the document are supported", ref)
+	}
+	ptr, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, c.errorf(loc, "invalid reference %q", ref)
+	}
+	tokens, err := dynamic.ParsePointer(ptr)
+	if err != nil {
+		return nil, c.errorf(loc, "invalid reference %q", ref)
+	}
+	v := c.root
+	for _, token := range tokens {
+		var ok bool
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v, ok = t[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if ok = err == nil && i >= 0 && i < len(t); ok {
+				v = t[i]
+			}
+		}
+		if !ok {
+			return nil, c.errorf(loc, "reference %q not found", ref)
+		}
+	}
+	return c.compile(v, tokens)
+}
+
+// child returns a copy of loc with tokens appended
+func child(loc []string, tokens ...string) []string {
+	return append(loc[:len(loc):len(loc)], tokens...)
+}
+
+// rat returns n as an exact rational number. An error is returned if n can
+// not be represented, such as when its exponent is too large.
+func rat(n json.Number) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return nil, fmt.Errorf("number %s is out of range", n)
+	}
+	return r, nil
+}
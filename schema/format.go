@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// formats contains the checks for the supported values of the format
+// keyword. Strings with any other format are not checked.
+var formats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"time": func(s string) bool {
+		_, err := time.Parse("15:04:05Z07:00", s)
+		return err == nil
+	},
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"hostname": isHostname,
+	"ipv4": func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && !strings.Contains(s, ":")
+	},
+	"ipv6": func(s string) bool {
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+	"uri-reference": func(s string) bool {
+		_, err := url.Parse(s)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+	"regex": func(s string) bool {
+		_, err := regexp.Compile(s)
+		return err == nil
+	},
+}
+
+var (
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	labelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !labelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+// Package schema validates dynamic.JSON and dynamic.Map values against JSON
+// Schema (draft 2020-12).
+//
+// The following keywords are supported:
+//
+//	type, enum, const
+//	properties, additionalProperties, required, minProperties, maxProperties
+//	prefixItems, items, minItems, maxItems
+//	allOf, anyOf, oneOf, not
+//	pattern, minLength, maxLength, format
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf
+//	$ref, $defs
+//
+// References must be JSON Pointer fragments within the same document, such as
+// "#/$defs/address". Patterns are compiled with the regexp package and
+// follow its syntax rather than ECMA 262. Unknown keywords are ignored.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/chanced/dynamic"
+)
+
+var (
+	ErrInvalidSchema    = errors.New("schema: invalid schema")
+	ErrValidationFailed = errors.New("schema: validation failed")
+)
+
+// Violation is a single failure of an instance to satisfy a schema
+type Violation struct {
+	// Location is the JSON Pointer of the offending value within the instance
+	Location string
+	// SchemaLocation is the JSON Pointer of the failing keyword within the
+	// schema
+	SchemaLocation string
+	// Keyword is the schema keyword which failed
+	Keyword string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%q: %s", v.Location, v.Message)
+}
+
+// ValidationError is returned when an instance does not satisfy a schema. It
+// contains every violation found.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("%v: %s", ErrValidationFailed, e.Violations[0])
+	}
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("%v: %d violations: %s", ErrValidationFailed, len(e.Violations), strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	location string
+	boolean  *bool
+
+	types    []string
+	enum     []dynamic.JSON
+	constant dynamic.JSON
+
+	properties           map[string]*Schema
+	additionalProperties *Schema
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+
+	prefixItems []*Schema
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+	ref   *Schema
+
+	pattern   *regexp.Regexp
+	minLength *int
+	maxLength *int
+	format    string
+
+	minimum          *big.Rat
+	maximum          *big.Rat
+	exclusiveMinimum *big.Rat
+	exclusiveMaximum *big.Rat
+	multipleOf       *big.Rat
+}
+
+// Compile compiles the JSON Schema data. An error wrapping ErrInvalidSchema
+// is returned if data is not a valid schema.
+func Compile(data dynamic.JSON) (*Schema, error) {
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{root: root, schemas: map[string]*Schema{}}
+	return c.compile(root, nil)
+}
+
+// MustCompile is like Compile but panics if data is not a valid schema
+func MustCompile(data dynamic.JSON) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate validates v against s. v may be a dynamic.JSON, []byte,
+// json.RawMessage, dynamic.Map or any other value which can be encoded as
+// json.
+//
+// A *ValidationError containing every violation is returned if v does not
+// satisfy s. A *dynamic.SyntaxError is returned if v is malformed json.
+func (s *Schema) Validate(v interface{}) error {
+	var data dynamic.JSON
+	switch t := v.(type) {
+	case dynamic.JSON:
+		data = t
+	case []byte:
+		data = t
+	case json.RawMessage:
+		data = dynamic.JSON(t)
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	if err := data.Validate(); err != nil {
+		return err
+	}
+	instance, err := decode(data)
+	if err != nil {
+		return err
+	}
+	vr := newValidator()
+	s.validateWith(vr, instance, nil)
+	if len(vr.violations) > 0 {
+		return &ValidationError{Violations: vr.violations}
+	}
+	return nil
+}
+
+// decode decodes data into generic values, retaining numbers as json.Number
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
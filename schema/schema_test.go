@@ -0,0 +1,210 @@
+package schema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chanced/dynamic"
+	"github.com/chanced/dynamic/schema"
+	"github.com/stretchr/testify/require"
+)
+
+var personSchema = dynamic.JSON(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 10},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"email": {"type": "string", "format": "email"},
+		"role": {"enum": ["admin", "user"]},
+		"version": {"const": 2},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "maxItems": 3},
+		"address": {"$ref": "#/$defs/address"},
+		"contact": {"oneOf": [{"type": "string"}, {"$ref": "#/$defs/address"}]},
+		"score": {"anyOf": [{"type": "null"}, {"type": "number", "multipleOf": 0.5}]},
+		"nick": {"allOf": [{"type": "string"}, {"not": {"const": "root"}}]}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {
+				"city": {"type": "string"},
+				"next": {"$ref": "#/$defs/address"}
+			}
+		}
+	}
+}`)
+
+func TestValidate(t *testing.T) {
+	assert := require.New(t)
+	s, err := schema.Compile(personSchema)
+	assert.NoError(err)
+
+	assert.NoError(s.Validate(dynamic.JSON(`{
+		"name": "Ann",
+		"age": 30,
+		"email": "ann@example.com",
+		"role": "admin",
+		"version": 2.0,
+		"tags": ["a", "b"],
+		"address": {"city": "Paris", "next": {"city": "Rome"}},
+		"contact": "phone",
+		"score": 1.5,
+		"nick": "annie"
+	}`)))
+	assert.NoError(s.Validate(dynamic.Map{"name": "Bob", "age": 3, "score": nil}))
+
+	err = s.Validate(dynamic.JSON(`{
+		"name": "",
+		"age": 150,
+		"email": "nope",
+		"role": "guest",
+		"version": 3,
+		"tags": ["a", "B", "c", "d"],
+		"address": {"next": {"city": 1}},
+		"contact": {"city": "Oslo"},
+		"score": 1.25,
+		"nick": "root",
+		"extra": true
+	}`))
+	var verr *schema.ValidationError
+	assert.True(errors.As(err, &verr))
+	assert.ErrorIs(err, schema.ErrValidationFailed)
+
+	type violation struct{ loc, keyword string }
+	var got []violation
+	for _, v := range verr.Violations {
+		got = append(got, violation{v.Location, v.Keyword})
+	}
+	assert.ElementsMatch([]violation{
+		{"/address", "required"},
+		{"/address/next/city", "type"},
+		{"/age", "exclusiveMaximum"},
+		{"/email", "format"},
+		{"/extra", ""},
+		{"/name", "minLength"},
+		{"/nick", "not"},
+		{"/role", "enum"},
+		{"/score", "anyOf"},
+		{"/tags", "maxItems"},
+		{"/tags/1", "pattern"},
+		{"/version", "const"},
+	}, got)
+
+	for _, v := range verr.Violations {
+		if v.Location == "/address/next/city" {
+			assert.Equal("/$defs/address/properties/city/type", v.SchemaLocation)
+			assert.Equal("expected string but got integer", v.Message)
+		}
+	}
+
+	err = s.Validate(dynamic.Map{"name": "x"})
+	assert.EqualError(err, `schema: validation failed: "": missing required property "age"`)
+
+	assert.ErrorIs(s.Validate(dynamic.JSON(`{"name":`)), dynamic.ErrMalformedJSON)
+}
+
+func TestValidateTypes(t *testing.T) {
+	assert := require.New(t)
+	s := schema.MustCompile(dynamic.JSON(`{"type": ["integer", "null"]}`))
+	assert.NoError(s.Validate(dynamic.JSON(`1.0`)))
+	assert.NoError(s.Validate(dynamic.JSON(`null`)))
+	assert.Error(s.Validate(dynamic.JSON(`1.5`)))
+
+	s = schema.MustCompile(dynamic.JSON(`{"prefixItems": [{"type": "string"}], "items": {"type": "number"}}`))
+	assert.NoError(s.Validate([]interface{}{"a", 1, 2.5}))
+	assert.Error(s.Validate([]interface{}{1, 1}))
+
+	for _, data := range []string{`{"type": "integer"}`, `{"minimum": 1}`, `{"multipleOf": 2}`} {
+		err := schema.MustCompile(dynamic.JSON(data)).Validate(dynamic.JSON(`1e99999999999`))
+		assert.ErrorIs(err, schema.ErrValidationFailed, data)
+		var verr *schema.ValidationError
+		assert.ErrorAs(err, &verr)
+		assert.Equal("number 1e99999999999 is out of range", verr.Violations[0].Message)
+	}
+	for _, data := range []string{`true`, `{}`, `{"type": "number"}`, `{"maxLength": 1}`} {
+		assert.NoError(schema.MustCompile(dynamic.JSON(data)).Validate(dynamic.JSON(`1e99999999999`)), data)
+	}
+	err := schema.MustCompile(dynamic.JSON(`{"type": "string"}`)).Validate(dynamic.JSON(`1e99999999999`))
+	var verr *schema.ValidationError
+	assert.ErrorAs(err, &verr)
+	assert.Equal("expected string but got number", verr.Violations[0].Message)
+
+	s = schema.MustCompile(dynamic.JSON(`false`))
+	assert.Error(s.Validate(dynamic.JSON(`{}`)))
+	s = schema.MustCompile(dynamic.JSON(`true`))
+	assert.NoError(s.Validate(dynamic.JSON(`{}`)))
+}
+
+func TestValidateFormats(t *testing.T) {
+	assert := require.New(t)
+	tests := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{
+		{"date-time", []string{"2021-03-04T05:06:07Z", "2021-03-04T05:06:07.5+01:00"}, []string{"2021-03-04", "2021-13-04T05:06:07Z"}},
+		{"date", []string{"2021-03-04"}, []string{"2021-3-4"}},
+		{"time", []string{"05:06:07Z"}, []string{"05:06"}},
+		{"email", []string{"a@b.co"}, []string{"Ann <a@b.co>", "a"}},
+		{"hostname", []string{"example.com"}, []string{"-bad.com", "a..b"}},
+		{"ipv4", []string{"127.0.0.1"}, []string{"::1", "256.0.0.1"}},
+		{"ipv6", []string{"::1"}, []string{"127.0.0.1"}},
+		{"uri", []string{"https://example.com/a?b"}, []string{"/relative"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567"}},
+	}
+	for _, test := range tests {
+		s := schema.MustCompile(dynamic.JSON(`{"format": "` + test.format + `"}`))
+		for _, v := range test.valid {
+			assert.NoError(s.Validate(v), "%s %s", test.format, v)
+		}
+		for _, v := range test.invalid {
+			assert.Error(s.Validate(v), "%s %s", test.format, v)
+		}
+	}
+}
+
+func TestValidateRefCycle(t *testing.T) {
+	assert := require.New(t)
+	s := schema.MustCompile(dynamic.JSON(`{"$defs": {"a": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`))
+	assert.NoError(s.Validate(1))
+
+	s = schema.MustCompile(dynamic.JSON(`{
+		"$defs": {
+			"a": {"allOf": [{"$ref": "#/$defs/b"}], "type": "integer"},
+			"b": {"anyOf": [{"$ref": "#/$defs/a"}, {"minimum": 0}]}
+		},
+		"$ref": "#/$defs/a"
+	}`))
+	assert.NoError(s.Validate(1))
+	assert.Error(s.Validate("x"))
+
+	s = schema.MustCompile(dynamic.JSON(`{"items": {"$ref": "#"}, "maxItems": 1}`))
+	assert.NoError(s.Validate(dynamic.JSON(`[[[]]]`)))
+	assert.Error(s.Validate(dynamic.JSON(`[[[1, 2]]]`)))
+}
+
+func TestCompileInvalid(t *testing.T) {
+	assert := require.New(t)
+	for _, data := range []string{
+		`1`,
+		`{"type": "int"}`,
+		`{"properties": {"a": 1}}`,
+		`{"required": [1]}`,
+		`{"minLength": -1}`,
+		`{"minLength": 1.5}`,
+		`{"pattern": "("}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema"}`,
+		`{"anyOf": []}`,
+		`{"multipleOf": 0}`,
+		`{"minimum": 1e99999999999}`,
+		`{"maxLength": 1e99999999999}`,
+	} {
+		_, err := schema.Compile(dynamic.JSON(data))
+		assert.ErrorIs(err, schema.ErrInvalidSchema, data)
+	}
+}
@@ -0,0 +1,281 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chanced/dynamic"
+)
+
+// validator collects the violations of an instance
+type validator struct {
+	violations []Violation
+	// refs holds the references being applied to each instance location so
+	// that reference cycles which do not descend into the instance terminate
+	refs map[refVisit]bool
+}
+
+type refVisit struct {
+	schema *Schema
+	path   string
+}
+
+func newValidator() *validator {
+	return &validator{refs: map[refVisit]bool{}}
+}
+
+func (vr *validator) report(s *Schema, path []string, keyword string, format string, args ...interface{}) {
+	loc := s.location + "/" + keyword
+	if keyword == "" {
+		loc = s.location
+	}
+	vr.violations = append(vr.violations, Violation{
+		Location:       dynamic.FormatPointer(path...),
+		SchemaLocation: loc,
+		Keyword:        keyword,
+		Message:        fmt.Sprintf(format, args...),
+	})
+}
+
+// valid reports whether v satisfies s without collecting violations
+func (s *Schema) valid(parent *validator, v interface{}, path []string) bool {
+	vr := &validator{refs: parent.refs}
+	s.validateWith(vr, v, path)
+	return len(vr.violations) == 0
+}
+
+func (s *Schema) validateWith(vr *validator, v interface{}, path []string) {
+	if s.boolean != nil {
+		if !*s.boolean {
+			vr.report(s, path, "", "no value is allowed")
+		}
+		return
+	}
+	if len(s.types) > 0 && !s.matchesType(v) {
+		if err := s.integerRange(v); err != nil {
+			vr.report(s, path, "type", "%v", err)
+		} else {
+			vr.report(s, path, "type", "expected %s but got %s", strings.Join(s.types, " or "), typeOf(v))
+		}
+	}
+	if s.enum != nil && !s.inEnum(v) {
+		vr.report(s, path, "enum", "value is not one of the allowed values")
+	}
+	if s.constant != nil && !equal(s.constant, v) {
+		vr.report(s, path, "const", "value must be %s", s.constant)
+	}
+	if s.ref != nil {
+		// a reference which is already being applied to v adds nothing
+		visit := refVisit{schema: s.ref, path: dynamic.FormatPointer(path...)}
+		if !vr.refs[visit] {
+			vr.refs[visit] = true
+			s.ref.validateWith(vr, v, path)
+			delete(vr.refs, visit)
+		}
+	}
+	for _, sub := range s.allOf {
+		sub.validateWith(vr, v, path)
+	}
+	if s.anyOf != nil {
+		matched := false
+		for _, sub := range s.anyOf {
+			if sub.valid(vr, v, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			vr.report(s, path, "anyOf", "value does not match any of the schemas")
+		}
+	}
+	if s.oneOf != nil {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.valid(vr, v, path) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			vr.report(s, path, "oneOf", "value matches %d of the schemas; expected exactly 1", matched)
+		}
+	}
+	if s.not != nil && s.not.valid(vr, v, path) {
+		vr.report(s, path, "not", "value must not match the schema")
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(vr, t, path)
+	case []interface{}:
+		s.validateArray(vr, t, path)
+	case string:
+		s.validateString(vr, t, path)
+	case json.Number:
+		s.validateNumber(vr, t, path)
+	}
+}
+
+func (s *Schema) validateObject(vr *validator, obj map[string]interface{}, path []string) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			vr.report(s, path, "required", "missing required property %q", name)
+		}
+	}
+	if s.minProperties != nil && len(obj) < *s.minProperties {
+		vr.report(s, path, "minProperties", "object has %d properties; expected at least %d", len(obj), *s.minProperties)
+	}
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		vr.report(s, path, "maxProperties", "object has %d properties; expected at most %d", len(obj), *s.maxProperties)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if sub, ok := s.properties[k]; ok {
+			sub.validateWith(vr, obj[k], child(path, k))
+		} else if s.additionalProperties != nil {
+			s.additionalProperties.validateWith(vr, obj[k], child(path, k))
+		}
+	}
+}
+
+func (s *Schema) validateArray(vr *validator, arr []interface{}, path []string) {
+	if s.minItems != nil && len(arr) < *s.minItems {
+		vr.report(s, path, "minItems", "array has %d items; expected at least %d", len(arr), *s.minItems)
+	}
+	if s.maxItems != nil && len(arr) > *s.maxItems {
+		vr.report(s, path, "maxItems", "array has %d items; expected at most %d", len(arr), *s.maxItems)
+	}
+	for i, v := range arr {
+		switch {
+		case i < len(s.prefixItems):
+			s.prefixItems[i].validateWith(vr, v, child(path, strconv.Itoa(i)))
+		case s.items != nil:
+			s.items.validateWith(vr, v, child(path, strconv.Itoa(i)))
+		}
+	}
+}
+
+func (s *Schema) validateString(vr *validator, str string, path []string) {
+	n := utf8.RuneCountInString(str)
+	if s.minLength != nil && n < *s.minLength {
+		vr.report(s, path, "minLength", "string has %d characters; expected at least %d", n, *s.minLength)
+	}
+	if s.maxLength != nil && n > *s.maxLength {
+		vr.report(s, path, "maxLength", "string has %d characters; expected at most %d", n, *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		vr.report(s, path, "pattern", "string does not match pattern %q", s.pattern)
+	}
+	if check, ok := formats[s.format]; ok && !check(str) {
+		vr.report(s, path, "format", "string is not a valid %s", s.format)
+	}
+}
+
+func (s *Schema) validateNumber(vr *validator, n json.Number, path []string) {
+	if s.minimum == nil && s.maximum == nil && s.exclusiveMinimum == nil &&
+		s.exclusiveMaximum == nil && s.multipleOf == nil {
+		return
+	}
+	r, err := rat(n)
+	if err != nil {
+		vr.report(s, path, "", "%v", err)
+		return
+	}
+	if s.minimum != nil && r.Cmp(s.minimum) < 0 {
+		vr.report(s, path, "minimum", "%s is less than the minimum of %s", n, ratString(s.minimum))
+	}
+	if s.maximum != nil && r.Cmp(s.maximum) > 0 {
+		vr.report(s, path, "maximum", "%s is greater than the maximum of %s", n, ratString(s.maximum))
+	}
+	if s.exclusiveMinimum != nil && r.Cmp(s.exclusiveMinimum) <= 0 {
+		vr.report(s, path, "exclusiveMinimum", "%s must be greater than %s", n, ratString(s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && r.Cmp(s.exclusiveMaximum) >= 0 {
+		vr.report(s, path, "exclusiveMaximum", "%s must be less than %s", n, ratString(s.exclusiveMaximum))
+	}
+	if s.multipleOf != nil && !new(big.Rat).Quo(r, s.multipleOf).IsInt() {
+		vr.report(s, path, "multipleOf", "%s is not a multiple of %s", n, ratString(s.multipleOf))
+	}
+}
+
+func (s *Schema) matchesType(v interface{}) bool {
+	t := typeOf(v)
+	for _, name := range s.types {
+		if name == t || (name == "number" && t == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// integerRange returns the error of a number which can not be checked
+// against the integer type because it is out of range
+func (s *Schema) integerRange(v interface{}) error {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil
+	}
+	for _, name := range s.types {
+		if name == "integer" {
+			_, err := rat(n)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) inEnum(v interface{}) bool {
+	for _, e := range s.enum {
+		if equal(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the json type name of v. Numbers without a fractional part
+// are reported as integers.
+func typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if r, err := rat(t); err == nil && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func equal(expected dynamic.JSON, v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return expected.SemanticEqual(data)
+}
+
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	return strings.TrimRight(r.FloatString(20), "0")
+}